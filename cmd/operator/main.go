@@ -57,6 +57,8 @@ var (
 	createCRD bool
 
 	clusterWide bool
+
+	workers int
 )
 
 func init() {
@@ -67,6 +69,7 @@ func init() {
 	flag.BoolVar(&createCRD, "create-crd", true, "The operator will not create the EtcdCluster CRD when this flag is set to false.")
 	flag.DurationVar(&gcInterval, "gc-interval", 10*time.Minute, "GC interval")
 	flag.BoolVar(&clusterWide, "cluster-wide", false, "Enable operator to watch clusters in all namespaces")
+	flag.IntVar(&workers, "workers", 4, "The number of clusters whose events are handled concurrently")
 	flag.Parse()
 }
 
@@ -165,7 +168,9 @@ func newControllerConfig(ctx context.Context) controller.Config {
 		KubeCli:        kubecli,
 		KubeExtCli:     k8sutil.MustNewKubeExtClient(),
 		EtcdCRCli:      client.MustNewInCluster(),
+		DynamicCli:     k8sutil.MustNewDynamicClient(),
 		CreateCRD:      createCRD,
+		Workers:        workers,
 	}
 
 	return cfg
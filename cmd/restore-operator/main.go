@@ -18,6 +18,7 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"runtime"
 	"time"
@@ -27,6 +28,7 @@ import (
 	"github.com/on2itsecurity/etcd-operator/pkg/util/constants"
 	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
 	version "github.com/on2itsecurity/etcd-operator/version"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/leaderelection"
@@ -37,6 +39,7 @@ var (
 	namespace   string
 	createCRD   bool
 	clusterWide bool
+	seedImage   string
 )
 
 const (
@@ -47,6 +50,7 @@ const (
 func init() {
 	flag.BoolVar(&createCRD, "create-crd", true, "The restore operator will not create the EtcdRestore CRD when this flag is set to false.")
 	flag.BoolVar(&clusterWide, "cluster-wide", false, "Enable operator to watch clusters in all namespaces")
+	flag.StringVar(&seedImage, "seed-image", "", "The image the seed members of direct restores read their backup with. Defaults to the image of the restore operator.")
 	flag.Parse()
 }
 
@@ -77,6 +81,12 @@ func main() {
 	if err != nil {
 		logrus.Fatalf("create service failed: %+v", err)
 	}
+	if len(seedImage) == 0 {
+		seedImage, err = imageOfMyself(context.TODO(), kubecli, name, namespace)
+		if err != nil {
+			logrus.Fatalf("get image of restore operator failed: %+v", err)
+		}
+	}
 
 	rl := &resourcelock.LeaseLock{
 		LeaseMeta: metav1.ObjectMeta{
@@ -91,7 +101,8 @@ func main() {
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-
+	http.Handle("/metrics", promhttp.Handler())
+	go http.ListenAndServe(fmt.Sprintf(":%d", 9091), nil)
 	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
 		Lock:          rl,
 		LeaseDuration: 15 * time.Second,
@@ -122,6 +133,7 @@ func newControllerConfig() controller.Config {
 		ClusterWide: clusterWide,
 		CreateCRD:   createCRD,
 		MySvcAddr:   fmt.Sprintf("%s.%s:%d", serviceNameForMyself, namespace, servicePortForMyself),
+		SeedImage:   seedImage,
 	}
 
 	return cfg
@@ -56,3 +56,15 @@ func createServiceForMyself(ctx context.Context, kubecli kubernetes.Interface, n
 	}
 	return nil
 }
+
+// imageOfMyself returns the image of the first container of the restore operator pod.
+func imageOfMyself(ctx context.Context, kubecli kubernetes.Interface, name, namespace string) (string, error) {
+	pod, err := kubecli.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if len(pod.Spec.Containers) == 0 {
+		return "", errors.Errorf("pod (%s) has no containers", name)
+	}
+	return pod.Spec.Containers[0].Image, nil
+}
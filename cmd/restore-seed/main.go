@@ -0,0 +1,56 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// restore-seed runs in the seed member of a restored cluster, and saves the backup
+// to restore from the backup storage, see spec.direct of the EtcdRestore.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/seed"
+	"github.com/on2itsecurity/etcd-operator/version"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	restoreSpec string
+	cfg         seed.Config
+)
+
+func init() {
+	flag.StringVar(&restoreSpec, "restore-spec", "", "The JSON encoded spec of the restore, with the exact path of the backup.")
+	flag.StringVar(&cfg.SourceSecretDir, "source-secret-dir", "", "The directory the secret of the restore source is mounted at.")
+	flag.StringVar(&cfg.EncryptionSecretDir, "encryption-secret-dir", "", "The directory the secret of the encryption key is mounted at.")
+	flag.StringVar(&cfg.Output, "output", "", "The file the backup is saved to.")
+	flag.Parse()
+}
+
+func main() {
+	logrus.Infof("etcd-restore-seed Version: %v", version.Version)
+	logrus.Infof("Git SHA: %s", version.GitSHA)
+
+	if err := json.Unmarshal([]byte(restoreSpec), &cfg.Spec); err != nil {
+		logrus.Fatalf("invalid restore spec: %v", err)
+	}
+	if err := seed.Fetch(context.Background(), cfg); err != nil {
+		// Like the other init containers of the seed member, report the error in its termination message.
+		os.WriteFile("/dev/termination-log", []byte(err.Error()), 0644)
+		logrus.Fatal(err)
+	}
+}
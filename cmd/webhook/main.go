@@ -0,0 +1,63 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/tls"
+	"flag"
+	"net/http"
+	"runtime"
+	"time"
+
+	_ "github.com/KimMachineGun/automemlimit"
+	"github.com/on2itsecurity/etcd-operator/pkg/webhook"
+	"github.com/on2itsecurity/etcd-operator/version"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	listenAddr string
+	certFile   string
+	keyFile    string
+)
+
+func init() {
+	flag.StringVar(&listenAddr, "listen-addr", ":8443", "The address on which the webhook server listens.")
+	flag.StringVar(&certFile, "tls-cert-file", "/etc/webhook/tls/tls.crt", "The serving certificate of the webhook server.")
+	flag.StringVar(&keyFile, "tls-private-key-file", "/etc/webhook/tls/tls.key", "The private key of the serving certificate.")
+	flag.Parse()
+}
+
+func main() {
+	logrus.Infof("Go Version: %s", runtime.Version())
+	logrus.Infof("Go OS/Arch: %s/%s", runtime.GOOS, runtime.GOARCH)
+	logrus.Infof("etcd-operator-webhook Version: %v", version.Version)
+	logrus.Infof("Git SHA: %s", version.GitSHA)
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		logrus.Fatalf("failed to load serving certificate: %v", err)
+	}
+
+	srv := &http.Server{
+		Addr:              listenAddr,
+		Handler:           webhook.NewHandler(),
+		TLSConfig:         &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	logrus.Infof("serving admission webhooks on %s", listenAddr)
+	logrus.Fatal(srv.ListenAndServeTLS("", ""))
+}
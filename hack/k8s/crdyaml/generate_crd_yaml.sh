@@ -4,3 +4,5 @@ GOBIN="$(go env GOBIN)"
 gobin="${GOBIN:-$(go env GOPATH)/bin}"
 
   "${gobin}/controller-gen" crd paths=./pkg/apis/etcd/v1beta2/... output:crd:artifacts:config=./example/crd
+  # The operators create the CRDs from the copies embedded in pkg/apis/etcd/crds.
+  cp ./example/crd/*.yaml ./pkg/apis/etcd/crds/
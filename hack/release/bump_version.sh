@@ -13,4 +13,4 @@ sed -i -e "s/${oldv}.*/${newv}/g" example/deployment.yaml
 sed -i -e "s/${oldv}.*/${newv}/g" example/deployment-ha.yaml
 sed -i -e "s/${oldv}.*/${newv}/g" example/etcd-backup-operator/deployment.yaml
 sed -i -e "s/${oldv}.*/${newv}/g" example/etcd-restore-operator/deployment.yaml
-
+sed -i -e "s/${oldv}.*/${newv}/g" example/webhook/deployment.yaml
@@ -0,0 +1,48 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package crds embeds the CustomResourceDefinitions generated from the etcd API types.
+// The manifests are generated by hack/k8s/crdyaml/generate_crd_yaml.sh, together with the ones in example/crd.
+package crds
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+//go:embed *.yaml
+var manifests embed.FS
+
+// Get returns the CustomResourceDefinition with the given name, for example "etcdclusters.etcd.database.coreos.com".
+func Get(crdName string) (*apiextensionsv1.CustomResourceDefinition, error) {
+	plural, group, ok := strings.Cut(crdName, ".")
+	if !ok {
+		return nil, fmt.Errorf("invalid CRD name: %s", crdName)
+	}
+	b, err := manifests.ReadFile(group + "_" + plural + ".yaml")
+	if err != nil {
+		return nil, fmt.Errorf("unknown CRD %s: %v", crdName, err)
+	}
+
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(b), len(b)).Decode(crd); err != nil {
+		return nil, fmt.Errorf("failed to decode CRD %s: %v", crdName, err)
+	}
+	return crd, nil
+}
@@ -0,0 +1,99 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crds
+
+import (
+	"testing"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name string
+		kind string
+		wErr bool
+	}{
+		{name: api.EtcdClusterCRDName, kind: api.EtcdClusterResourceKind},
+		{name: api.EtcdBackupCRDName, kind: api.EtcdBackupResourceKind},
+		{name: api.EtcdRestoreCRDName, kind: api.EtcdRestoreResourceKind},
+		{name: "unknown.etcd.database.coreos.com", wErr: true},
+		{name: "invalid", wErr: true},
+	}
+	for i, tt := range tests {
+		crd, err := Get(tt.name)
+		if (err != nil) != tt.wErr {
+			t.Fatalf("#%d: err get=%v, want err=%v", i, err, tt.wErr)
+		}
+		if tt.wErr {
+			continue
+		}
+		if crd.Name != tt.name || crd.Spec.Names.Kind != tt.kind {
+			t.Errorf("#%d: get=%s/%s, want=%s/%s", i, crd.Name, crd.Spec.Names.Kind, tt.name, tt.kind)
+		}
+		v := crd.Spec.Versions[0]
+		if v.Name != api.SchemeGroupVersion.Version || v.Schema == nil || v.Subresources == nil || v.Subresources.Status == nil {
+			t.Errorf("#%d: version %s lacks the schema or the status subresource", i, v.Name)
+		}
+	}
+}
+
+func TestClusterSchema(t *testing.T) {
+	crd, err := Get(api.EtcdClusterCRDName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := crd.Spec.Versions[0]
+	size := v.Schema.OpenAPIV3Schema.Properties["spec"].Properties["size"]
+	if size.Minimum == nil || *size.Minimum != 1 || size.Maximum == nil || *size.Maximum != api.MaxClusterSize {
+		t.Errorf("size bounds get=%v..%v, want=1..%d", size.Minimum, size.Maximum, api.MaxClusterSize)
+	}
+	if phase := v.Schema.OpenAPIV3Schema.Properties["status"].Properties["phase"]; len(phase.Enum) == 0 {
+		t.Errorf("phase has no enum")
+	}
+	if len(v.AdditionalPrinterColumns) != 4 {
+		t.Errorf("printer columns get=%d, want=4", len(v.AdditionalPrinterColumns))
+	}
+}
+
+// requiredFields returns the required fields of s and of the schemas nested in it.
+func requiredFields(s *apiextensionsv1.JSONSchemaProps) []string {
+	required := append([]string{}, s.Required...)
+	for _, p := range s.Properties {
+		required = append(required, requiredFields(&p)...)
+	}
+	if s.Items != nil && s.Items.Schema != nil {
+		required = append(required, requiredFields(s.Items.Schema)...)
+	}
+	return required
+}
+
+func TestOptionalFields(t *testing.T) {
+	// Existing resources omit these fields, the API server would reject them if they were required.
+	optional := map[string]bool{"allowSelfSignedCertificates": true, "forcePathStyle": true}
+	for _, name := range []string{api.EtcdClusterCRDName, api.EtcdBackupCRDName, api.EtcdRestoreCRDName} {
+		crd, err := Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range requiredFields(crd.Spec.Versions[0].Schema.OpenAPIV3Schema) {
+			if optional[f] {
+				t.Errorf("%s: %s is required", name, f)
+			}
+		}
+	}
+}
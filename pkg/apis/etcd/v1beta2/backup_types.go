@@ -15,6 +15,11 @@
 package v1beta2
 
 import (
+	"errors"
+	"fmt"
+
+	"github.com/on2itsecurity/etcd-operator/pkg/util/cronutil"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -39,10 +44,41 @@ const (
 	BackupStorageTypeOSS                         BackupStorageType = "OSS"
 	AlibabaCloudSecretCredentialsAccessKeyID                       = "accessKeyID"
 	AlibabaCloudSecretCredentialsAccessKeySecret                   = "accessKeySecret"
+
+	// Local volume related consts
+	BackupStorageTypeLocal BackupStorageType = "Local"
+
+	// Compression codecs of backups
+	BackupCompressionNone BackupCompression = ""
+	BackupCompressionGzip BackupCompression = "gzip"
+	BackupCompressionZstd BackupCompression = "zstd"
+
+	// MaxBackupHistory is the number of backup attempts kept in the history of the EtcdBackup status.
+	MaxBackupHistory = 10
+
+	// DefaultEncryptionKey is the key of the secret data holding the backup encryption key, if not set.
+	DefaultEncryptionKey = "encryption-key"
 )
 
+// BackupStorageType is the type of the storage a backup is saved to.
+// +kubebuilder:validation:Enum=S3;ABS;GCS;OSS;Local
 type BackupStorageType string
 
+// BackupCompression is the codec backups are compressed with.
+// +kubebuilder:validation:Enum="";gzip;zstd
+type BackupCompression string
+
+// Extension returns the file name extension of backups compressed with c.
+func (c BackupCompression) Extension() string {
+	switch c {
+	case BackupCompressionGzip:
+		return ".gz"
+	case BackupCompressionZstd:
+		return ".zst"
+	}
+	return ""
+}
+
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +k8s:openapi-gen=true
 
@@ -54,6 +90,7 @@ type EtcdBackupList struct {
 }
 
 // +genclient
+// +kubebuilder:subresource:status
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +k8s:openapi-gen=true
 
@@ -72,6 +109,11 @@ type BackupSpec struct {
 	// the backup from the endpoint that has the most up-to-date state.
 	// The given endpoints must belong to the same etcd cluster.
 	EtcdEndpoints []string `json:"etcdEndpoints,omitempty"`
+	// EtcdClusterRef references the EtcdCluster to back up, in the namespace of the backup.
+	// The endpoints are resolved from the client service of the cluster, and the client
+	// TLS secret from its TLS policy, so EtcdEndpoints and ClientTLSSecret must not be set.
+	// Backups fail while the cluster is not Running.
+	EtcdClusterRef *EtcdClusterRef `json:"etcdClusterRef,omitempty"`
 	// StorageType is the etcd backup storage type.
 	// We need this field because CRD doesn't support validation against invalid fields
 	// and we cannot verify invalid backup storage source.
@@ -89,7 +131,78 @@ type BackupSpec struct {
 	ClientTLSSecret string `json:"clientTLSSecret,omitempty"`
 	// If AllowSelfSignedCertificates is true, set the InsecureSkipVerify flag
 	// for TLS connections
-	AllowSelfSignedCertificates bool `json:"allowSelfSignedCertificates"`
+	AllowSelfSignedCertificates bool `json:"allowSelfSignedCertificates,omitempty"`
+	// Compression is the codec the backups are compressed with, "gzip" or "zstd".
+	// Names of periodic backups get the extension of the codec, ".gz" or ".zst".
+	// If not set, backups are saved uncompressed.
+	Compression BackupCompression `json:"compression,omitempty"`
+	// Encryption encrypts the backups before they are saved.
+	// If not set, backups are saved unencrypted.
+	Encryption *BackupEncryption `json:"encryption,omitempty"`
+}
+
+func (bs *BackupSpec) Validate() error {
+	if bs.EtcdClusterRef != nil {
+		if len(bs.EtcdClusterRef.Name) == 0 {
+			return errors.New("spec.etcdClusterRef.name should not be empty")
+		}
+		if len(bs.EtcdEndpoints) != 0 || len(bs.ClientTLSSecret) != 0 {
+			return errors.New("spec.etcdEndpoints and spec.clientTLSSecret should not be set with spec.etcdClusterRef")
+		}
+	} else if len(bs.EtcdEndpoints) == 0 {
+		return errors.New("spec.etcdEndpoints should not be empty")
+	}
+	if err := bs.Encryption.Validate(); err != nil {
+		return err
+	}
+	if bs.BackupPolicy != nil {
+		if bs.BackupPolicy.BackupIntervalInSecond < 0 {
+			return errors.New("spec.BackupPolicy.BackupIntervalInSecond should not be lower than 0")
+		}
+		if bs.BackupPolicy.MaxBackups < 0 {
+			return errors.New("spec.BackupPolicy.MaxBackups should not be lower than 0")
+		}
+		if len(bs.BackupPolicy.Schedule) != 0 {
+			if bs.BackupPolicy.BackupIntervalInSecond != 0 {
+				return errors.New("spec.BackupPolicy.Schedule and spec.BackupPolicy.BackupIntervalInSecond should not both be set")
+			}
+			if _, err := cronutil.Parse(bs.BackupPolicy.Schedule, bs.BackupPolicy.TimeZone); err != nil {
+				return fmt.Errorf("spec.BackupPolicy.Schedule: %v", err)
+			}
+		} else if len(bs.BackupPolicy.TimeZone) != 0 {
+			return errors.New("spec.BackupPolicy.TimeZone should only be set with spec.BackupPolicy.Schedule")
+		}
+		if err := bs.BackupPolicy.Retention.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BackupEncryption references the key backups are encrypted with.
+// Backups are encrypted with AES-256-GCM, in chunks, so that they can be streamed.
+type BackupEncryption struct {
+	// Secret is the name of the secret in the namespace of the backup that holds the key.
+	Secret string `json:"secret"`
+	// Key is the key of the secret data holding the 32 bytes of the AES-256 key.
+	// Defaults to "encryption-key".
+	Key string `json:"key,omitempty"`
+}
+
+// Validate checks that the encryption references a secret, it accepts a nil encryption.
+func (e *BackupEncryption) Validate() error {
+	if e != nil && len(e.Secret) == 0 {
+		return errors.New("spec.encryption.secret should not be empty")
+	}
+	return nil
+}
+
+// KeyOrDefault returns the key of the secret data holding the encryption key.
+func (e *BackupEncryption) KeyOrDefault() string {
+	if len(e.Key) == 0 {
+		return DefaultEncryptionKey
+	}
+	return e.Key
 }
 
 // BackupSource contains the supported backup sources.
@@ -102,6 +215,8 @@ type BackupSource struct {
 	GCS *GCSBackupSource `json:"gcs,omitempty"`
 	// OSS defines the OSS backup source spec.
 	OSS *OSSBackupSource `json:"oss,omitempty"`
+	// Local defines the local volume backup source spec.
+	Local *LocalBackupSource `json:"local,omitempty"`
 }
 
 // BackupPolicy defines backup policy.
@@ -114,6 +229,59 @@ type BackupPolicy struct {
 	// MaxBackups is to specify how many backups we want to keep
 	// 0 is magic number to indicate un-limited backups
 	MaxBackups int `json:"maxBackups,omitempty"`
+	// Schedule is to specify when operator take snapshot, in cron syntax,
+	// e.g. "30 2 * * *" for every day at 02:30. It cannot be combined with BackupIntervalInSecond.
+	// The fields are minute, hour, day of month, month and day of week,
+	// and the descriptors "@yearly", "@monthly", "@weekly", "@daily" and "@hourly" are accepted too.
+	Schedule string `json:"schedule,omitempty"`
+	// TimeZone is the time zone Schedule is evaluated in, a name of the IANA time zone database
+	// such as "Europe/Amsterdam". Defaults to UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+	// Retention is to specify which periodic backups we want to keep, in addition to the newest MaxBackups.
+	// If not set, only MaxBackups applies.
+	Retention *BackupRetention `json:"retention,omitempty"`
+}
+
+// BackupRetention is a grandfather-father-son retention policy for periodic backups.
+// For each period, the newest backup of each of the newest periods that have a backup is kept,
+// e.g. Daily: 14 keeps the newest backup of each of the last 14 days with backups.
+// Periods are evaluated in BackupPolicy.TimeZone. The newest backup is always kept.
+type BackupRetention struct {
+	// Hourly is the number of hourly backups to keep.
+	Hourly int `json:"hourly,omitempty"`
+	// Daily is the number of daily backups to keep.
+	Daily int `json:"daily,omitempty"`
+	// Weekly is the number of weekly backups to keep, weeks start on Monday.
+	Weekly int `json:"weekly,omitempty"`
+	// Monthly is the number of monthly backups to keep.
+	Monthly int `json:"monthly,omitempty"`
+	// Yearly is the number of yearly backups to keep.
+	Yearly int `json:"yearly,omitempty"`
+	// MaxAgeInSecond is the maximal age of backups, older backups are pruned even if they would be kept otherwise.
+	// 0 is magic number to indicate backups of any age.
+	MaxAgeInSecond int64 `json:"maxAgeInSecond,omitempty"`
+	// If DryRun is true, backups are not pruned, instead an event is recorded that lists the backups that would be,
+	// and the backup operator logs their paths.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// Validate checks that the retention counts are not negative, it accepts a nil retention.
+func (r *BackupRetention) Validate() error {
+	if r == nil {
+		return nil
+	}
+	if r.Hourly < 0 || r.Daily < 0 || r.Weekly < 0 || r.Monthly < 0 || r.Yearly < 0 {
+		return errors.New("spec.BackupPolicy.Retention counts should not be lower than 0")
+	}
+	if r.MaxAgeInSecond < 0 {
+		return errors.New("spec.BackupPolicy.Retention.MaxAgeInSecond should not be lower than 0")
+	}
+	return nil
+}
+
+// IsPeriodic tells whether backups are taken at an interval or a schedule, rather than once.
+func (bp *BackupPolicy) IsPeriodic() bool {
+	return bp != nil && (bp.BackupIntervalInSecond != 0 || len(bp.Schedule) != 0)
 }
 
 // BackupStatus represents the status of the EtcdBackup Custom Resource.
@@ -131,6 +299,32 @@ type BackupStatus struct {
 	// Last execution date. First it will be creation timestamp, later on it will be last execution date despite successful or failed run.
 	// This field is used when pod is restarted ticked should be create from this timestamp not current timestamp
 	LastExecutionDate metav1.Time `json:"lastExecutionDate,omitempty"`
+	// NextScheduledDate is the time of the next backup of a backup with a schedule.
+	NextScheduledDate metav1.Time `json:"nextScheduledDate,omitempty"`
+	// LastFailureDate is the time of the last failed backup.
+	LastFailureDate metav1.Time `json:"lastFailureDate,omitempty"`
+	// ConsecutiveFailures is the number of backups that failed since the last successful backup.
+	ConsecutiveFailures int `json:"consecutiveFailures,omitempty"`
+	// History lists the most recent backup attempts, newest first, at most 10.
+	History []BackupAttempt `json:"history,omitempty"`
+}
+
+// BackupAttempt is an attempt to take a backup.
+type BackupAttempt struct {
+	// Path is the path of the snapshot in the backup storage, if it was saved.
+	Path string `json:"path,omitempty"`
+	// Size is the size of the snapshot in bytes, before it was compressed or encrypted.
+	Size int64 `json:"size,omitempty"`
+	// EtcdRevision is the revision of etcd's KV store the snapshot was taken at.
+	EtcdRevision int64 `json:"etcdRevision,omitempty"`
+	// StartTime is the time the attempt started.
+	StartTime metav1.Time `json:"startTime"`
+	// Duration is how long the attempt took.
+	Duration metav1.Duration `json:"duration"`
+	// Succeeded indicates if the attempt has succeeded.
+	Succeeded bool `json:"succeeded"`
+	// Reason indicates the reason the attempt failed.
+	Reason string `json:"reason,omitempty"`
 }
 
 // S3BackupSource provides the spec how to store backups on S3.
@@ -155,7 +349,7 @@ type S3BackupSource struct {
 	// ForcePathStyle forces to use path style over the default subdomain style.
 	// This is useful when you have an s3 compatible endpoint that doesn't support
 	// subdomain buckets.
-	ForcePathStyle bool `json:"forcePathStyle"`
+	ForcePathStyle bool `json:"forcePathStyle,omitempty"`
 }
 
 // ABSBackupSource provides the spec how to store backups on ABS.
@@ -219,3 +413,11 @@ type OSSBackupSource struct {
 	//  https://www.alibabacloud.com/help/doc-detail/31837.htm
 	Endpoint string `json:"endpoint,omitempty"`
 }
+
+// LocalBackupSource provides the spec how to store backups on a volume mounted into the backup operator,
+// for example a PersistentVolumeClaim.
+type LocalBackupSource struct {
+	// Path is the path of the backup file relative to the mount directory of the volume, "/var/etcd-backup".
+	// e.g: "mycluster/etcd.backup"
+	Path string `json:"path"`
+}
@@ -16,8 +16,10 @@ package v1beta2
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
+	"github.com/coreos/go-semver/semver"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -25,6 +27,9 @@ import (
 const (
 	defaultRepository  = "gcr.io/etcd-development/etcd"
 	DefaultEtcdVersion = "v3.6.10"
+
+	// MaxClusterSize is the largest supported cluster size.
+	MaxClusterSize = 7
 )
 
 var (
@@ -45,6 +50,12 @@ type EtcdClusterList struct {
 }
 
 // +genclient
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:shortName=etcd
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Size",type=integer,JSONPath=`.spec.size`
+// +kubebuilder:printcolumn:name="Version",type=string,JSONPath=`.status.currentVersion`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +k8s:openapi-gen=true
 
@@ -72,6 +83,8 @@ type ClusterSpec struct {
 	// The etcd-operator will eventually make the size of the running
 	// cluster equal to the expected size.
 	// The vaild range of the size is from 1 to 7.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=7
 	Size int `json:"size"`
 	// Repository is the name of the repository that hosts
 	// etcd container images. It should be direct clone of the repository in official
@@ -91,14 +104,20 @@ type ClusterSpec struct {
 	// Only etcd released versions are supported: https://github.com/etcd-io/etcd/releases
 	//
 	// If version is not set, default is "v3.6.10".
+	//
+	// Downgrades are supported to an earlier patch of the same minor version, and from v3.6 on
+	// to the previous minor version, which the operator performs with the downgrade API of etcd.
 	Version string `json:"version,omitempty"`
 
 	// Paused is to pause the control of the operator for the etcd cluster.
+	// A cluster that is created paused is only bootstrapped once it is unpaused,
+	// unless its status is set to a running phase.
 	Paused bool `json:"paused,omitempty"`
 
 	// Pod defines the policy to create pod for the etcd pod.
 	//
-	// Updating Pod does not take effect on any existing etcd pods.
+	// Updating Pod replaces the existing etcd members one at a time,
+	// except for the fields that cannot be updated.
 	Pod *PodPolicy `json:"pod,omitempty"`
 
 	// Service defines the policy to create etcd services
@@ -109,6 +128,17 @@ type ClusterSpec struct {
 
 	// etcd cluster TLS configuration
 	TLS *TLSPolicy `json:"TLS,omitempty"`
+
+	// Recovery defines how the operator recovers the etcd cluster after it lost quorum.
+	// If not set, a cluster that lost quorum is marked as failed.
+	Recovery *RecoveryPolicy `json:"recovery,omitempty"`
+
+	// Backup defines the EtcdBackup the operator creates for the cluster and keeps in sync with this policy.
+	// If not set, the operator deletes the EtcdBackup it created.
+	Backup *ClusterBackupPolicy `json:"backup,omitempty"`
+
+	// UpgradePolicy defines how the operator upgrades the etcd cluster to a new version.
+	UpgradePolicy *UpgradePolicy `json:"upgradePolicy,omitempty"`
 }
 
 // PodPolicy defines the policy to create pod for the etcd container.
@@ -130,7 +160,6 @@ type PodPolicy struct {
 	AntiAffinity bool `json:"antiAffinity,omitempty"`
 
 	// Resources is the resource requirements for the etcd container.
-	// This field cannot be updated once the cluster is created.
 	Resources v1.ResourceRequirements `json:"resources,omitempty"`
 
 	// Tolerations specifies the pod's tolerations.
@@ -143,15 +172,22 @@ type PodPolicy struct {
 	// This is used to configure etcd process. etcd cluster cannot be created, when
 	// bad environement variables are provided. Do not overwrite any flags used to
 	// bootstrap the cluster (for example `--initial-cluster` flag).
-	// This field cannot be updated.
 	EtcdEnv []v1.EnvVar `json:"etcdEnv,omitempty"`
 
 	// PersistentVolumeClaimSpec is the spec to describe PVC for the etcd container
 	// This field is optional. If no PVC spec, etcd container will use emptyDir as volume
-	// Note. This feature is in alpha stage. It is currently only used as non-stable storage,
-	// not the stable storage. Future work need to make it used as stable storage.
+	// Note. This feature is in alpha stage. Unless StableStorage is set, it is only used
+	// as non-stable storage: a dead member and its PVC are removed and replaced by a new member.
 	PersistentVolumeClaimSpec *v1.PersistentVolumeClaimSpec `json:"persistentVolumeClaimSpec,omitempty"`
 
+	// StableStorage makes the PVC of a member survive the loss of its pod.
+	// A dead member's pod is recreated with the same member name on its existing PVC,
+	// so that it rejoins the cluster with its data instead of being replaced.
+	// The member is only removed and replaced when its PVC is gone or lost, or when
+	// the recreated pod repeatedly fails to become ready.
+	// No effect if PersistentVolumeClaimSpec is not set.
+	StableStorage bool `json:"stableStorage,omitempty"`
+
 	// Annotations specifies the annotations to attach to pods the operator creates for the
 	// etcd cluster.
 	// The "etcd.version" annotation is reserved for the internal use of the etcd operator.
@@ -202,6 +238,16 @@ type ServicePolicy struct {
 
 // TODO: move this to initializer
 func (c *ClusterSpec) Validate() error {
+	if c.Size < 1 || c.Size > MaxClusterSize {
+		return fmt.Errorf("spec: size must be between 1 and %d", MaxClusterSize)
+	}
+
+	if len(c.Version) != 0 {
+		if _, err := parseVersion(c.Version); err != nil {
+			return fmt.Errorf("spec: invalid version %q: %v", c.Version, err)
+		}
+	}
+
 	if c.TLS != nil {
 		if err := c.TLS.Validate(); err != nil {
 			return err
@@ -215,9 +261,84 @@ func (c *ClusterSpec) Validate() error {
 			}
 		}
 	}
+
+	if c.Recovery != nil && c.Recovery.FromMembers {
+		if c.Pod == nil || c.Pod.PersistentVolumeClaimSpec == nil {
+			return errors.New("spec: recovery from members requires pod.persistentVolumeClaimSpec")
+		}
+	}
+
+	if err := c.Backup.Validate(); err != nil {
+		return err
+	}
+
+	if c.UpgradePolicy != nil && c.UpgradePolicy.BackupBeforeUpgrade {
+		if c.Backup == nil {
+			return errors.New("spec: upgradePolicy.backupBeforeUpgrade requires backup")
+		}
+		if c.Backup.StorageType == BackupStorageTypeLocal {
+			return errors.New("spec: upgradePolicy.backupBeforeUpgrade does not support Local backup storage")
+		}
+	}
 	return nil
 }
 
+// ValidateUpdate checks that the spec can be changed from old to c.
+// It rejects unsupported downgrades, see ValidateVersionChange,
+// and enabling, disabling or switching the kind of TLS.
+func (c *ClusterSpec) ValidateUpdate(old ClusterSpec) error {
+	if len(old.Version) != 0 && len(c.Version) != 0 {
+		if err := ValidateVersionChange(old.Version, c.Version); err != nil {
+			return fmt.Errorf("spec: %v", err)
+		}
+	}
+
+	if c.TLS.IsSecureClient() != old.TLS.IsSecureClient() || c.TLS.IsSecurePeer() != old.TLS.IsSecurePeer() ||
+		c.TLS.IsOperatorManaged() != old.TLS.IsOperatorManaged() || c.TLS.IsCertManagerManaged() != old.TLS.IsCertManagerManaged() {
+		return errors.New("spec: TLS cannot be enabled, disabled or switched between static, operator and certManager")
+	}
+	return nil
+}
+
+func parseVersion(v string) (*semver.Version, error) {
+	return semver.NewVersion(strings.TrimPrefix(v, "v"))
+}
+
+// minDowngradeVersion is the first version of etcd with the downgrade API.
+var minDowngradeVersion = semver.Version{Major: 3, Minor: 6}
+
+// ValidateVersionChange checks that an etcd cluster can change from version from to version to.
+// Upgrades and downgrades to an earlier patch of the same minor version replace the members one at a time.
+// Downgrades to the previous minor version are supported from v3.6, with the downgrade API of etcd.
+// Versions that cannot be parsed are left to the validation of the spec.
+func ValidateVersionChange(from, to string) error {
+	fv, ferr := parseVersion(from)
+	tv, terr := parseVersion(to)
+	if ferr != nil || terr != nil || !tv.LessThan(*fv) {
+		return nil
+	}
+	if tv.Major == fv.Major && tv.Minor == fv.Minor {
+		return nil
+	}
+	if tv.Major != fv.Major || tv.Minor+1 != fv.Minor {
+		return fmt.Errorf("downgrading from version %s to %s is not supported, only downgrades to the previous minor version are", from, to)
+	}
+	if fv.LessThan(minDowngradeVersion) {
+		return fmt.Errorf("downgrading from version %s to %s is not supported, downgrades require version v%s or later", from, to, minDowngradeVersion)
+	}
+	return nil
+}
+
+// IsMinorDowngrade tells whether version to has an earlier minor version than version from.
+func IsMinorDowngrade(from, to string) bool {
+	fv, ferr := parseVersion(from)
+	tv, terr := parseVersion(to)
+	if ferr != nil || terr != nil {
+		return false
+	}
+	return tv.Major < fv.Major || (tv.Major == fv.Major && tv.Minor < fv.Minor)
+}
+
 // SetDefaults cleans up user passed spec, e.g. defaulting, transforming fields.
 // TODO: move this to initializer
 func (e *EtcdCluster) SetDefaults() {
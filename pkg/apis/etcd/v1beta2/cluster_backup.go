@@ -0,0 +1,64 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta2
+
+import "fmt"
+
+// ClusterBackupPolicy defines the EtcdBackup the operator maintains for an etcd cluster.
+// The EtcdBackup is named after the cluster, see EtcdBackupName, references the cluster
+// and is owned by it, so that it is deleted with the cluster.
+// This requires the etcd-backup-operator to be running.
+type ClusterBackupPolicy struct {
+	// StorageType is the etcd backup storage type.
+	StorageType BackupStorageType `json:"storageType"`
+	// BackupPolicy configures the backup process, e.g. the schedule and retention of periodic backups.
+	BackupPolicy *BackupPolicy `json:"backupPolicy,omitempty"`
+	// BackupSource is the backup storage source.
+	BackupSource `json:",inline"`
+	// Compression is the codec the backups are compressed with, "gzip" or "zstd".
+	Compression BackupCompression `json:"compression,omitempty"`
+	// Encryption encrypts the backups before they are saved.
+	Encryption *BackupEncryption `json:"encryption,omitempty"`
+}
+
+// EtcdBackupName returns the name of the EtcdBackup of the cluster clusterName.
+func EtcdBackupName(clusterName string) string {
+	return clusterName + "-backup"
+}
+
+// BackupSpec returns the spec of the EtcdBackup of the cluster clusterName.
+func (bp *ClusterBackupPolicy) BackupSpec(clusterName string) BackupSpec {
+	return BackupSpec{
+		EtcdClusterRef: &EtcdClusterRef{Name: clusterName},
+		StorageType:    bp.StorageType,
+		BackupPolicy:   bp.BackupPolicy.DeepCopy(),
+		BackupSource:   *bp.BackupSource.DeepCopy(),
+		Compression:    bp.Compression,
+		Encryption:     bp.Encryption.DeepCopy(),
+	}
+}
+
+// Validate checks the backup policy, it accepts a nil policy.
+func (bp *ClusterBackupPolicy) Validate() error {
+	if bp == nil {
+		return nil
+	}
+	// The name of the cluster is not known here, it does not affect the validation.
+	spec := bp.BackupSpec("cluster")
+	if err := spec.Validate(); err != nil {
+		return fmt.Errorf("spec.backup: %v", err)
+	}
+	return nil
+}
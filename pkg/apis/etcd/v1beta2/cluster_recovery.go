@@ -0,0 +1,33 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta2
+
+// RecoveryPolicy defines how the operator recovers an etcd cluster that lost quorum.
+// When both sources are set, the surviving members are preferred over the backup.
+type RecoveryPolicy struct {
+	// FromMembers recovers the cluster from the surviving member with the highest revision.
+	// That member is restarted on its PVC with `--force-new-cluster`, the other members
+	// and their PVCs are removed, and the cluster is scaled back up to the desired size.
+	// Writes that were not replicated to the chosen member are lost.
+	//
+	// Requires Pod.PersistentVolumeClaimSpec to be set.
+	FromMembers bool `json:"fromMembers,omitempty"`
+
+	// EtcdBackup is the name of an EtcdBackup in the same namespace as the cluster.
+	// If the cluster cannot be recovered from its members, it is restored from the newest
+	// snapshot taken by this EtcdBackup by creating an EtcdRestore with the cluster's name.
+	// This requires the etcd-restore-operator to be running.
+	EtcdBackup string `json:"etcdBackup,omitempty"`
+}
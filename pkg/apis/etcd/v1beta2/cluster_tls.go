@@ -14,13 +14,26 @@
 
 package v1beta2
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
+
+const (
+	defaultCertificateValidityInDays = 365
+	defaultRenewBeforeInDays         = 30
+)
 
 // TLSPolicy defines the TLS policy of an etcd cluster
 type TLSPolicy struct {
 	// StaticTLS enables user to generate static x509 certificates and keys,
 	// put them into Kubernetes secrets, and specify them into here.
 	Static *StaticTLS `json:"static,omitempty"`
+	// OperatorTLS makes the operator issue the certificates of the cluster from
+	// a self-signed CA it generates, and renew them before they expire.
+	Operator *OperatorTLS `json:"operator,omitempty"`
+	// CertManager makes the operator request the certificates of the cluster from cert-manager.
+	CertManager *CertManagerTLS `json:"certManager,omitempty"`
 }
 
 type StaticTLS struct {
@@ -40,7 +53,86 @@ type MemberSecret struct {
 	ServerSecret string `json:"serverSecret,omitempty"`
 }
 
+// OperatorTLS defines the certificates issued by the operator.
+// The CA, peer, server and operator client certificates are stored in the secrets
+// "<cluster-name>-ca-tls", "<cluster-name>-peer-tls", "<cluster-name>-server-tls" and
+// "<cluster-name>-operator-tls". Clients of the cluster can use the operator secret.
+type OperatorTLS struct {
+	// CertificateValidityInDays is how long the peer, server and operator client certificates are valid.
+	// Defaults to 365 days. The CA certificate is valid for 10 years.
+	CertificateValidityInDays int `json:"certificateValidityInDays,omitempty"`
+	// RenewBeforeInDays is how long before their expiry the certificates are renewed.
+	// Defaults to 30 days.
+	RenewBeforeInDays int `json:"renewBeforeInDays,omitempty"`
+}
+
+// CertManagerTLS defines the cert-manager issuer that signs the certificates of the cluster.
+// The operator creates the cert-manager Certificates "<cluster-name>-peer-tls", "<cluster-name>-server-tls"
+// and "<cluster-name>-operator-tls", which are stored in secrets with the same names.
+type CertManagerTLS struct {
+	// IssuerRef references the cert-manager Issuer or ClusterIssuer that signs the certificates.
+	// The issuer must add its CA to the secrets as ca.crt, like the CA and Vault issuers do.
+	IssuerRef CertManagerIssuerRef `json:"issuerRef"`
+}
+
+// CertManagerIssuerRef references a cert-manager issuer.
+type CertManagerIssuerRef struct {
+	// Name is the name of the issuer.
+	Name string `json:"name"`
+	// Kind is the kind of the issuer, either "Issuer" or "ClusterIssuer".
+	// Defaults to "Issuer", which must be in the namespace of the cluster.
+	Kind string `json:"kind,omitempty"`
+	// Group is the API group of the issuer. Defaults to "cert-manager.io".
+	Group string `json:"group,omitempty"`
+}
+
+// CertificateValidity returns how long the issued certificates are valid.
+func (ot *OperatorTLS) CertificateValidity() time.Duration {
+	days := defaultCertificateValidityInDays
+	if ot.CertificateValidityInDays > 0 {
+		days = ot.CertificateValidityInDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// RenewBefore returns how long before their expiry the issued certificates are renewed.
+func (ot *OperatorTLS) RenewBefore() time.Duration {
+	days := defaultRenewBeforeInDays
+	if ot.RenewBeforeInDays > 0 {
+		days = ot.RenewBeforeInDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
 func (tp *TLSPolicy) Validate() error {
+	n := 0
+	for _, set := range []bool{tp.Static != nil, tp.Operator != nil, tp.CertManager != nil} {
+		if set {
+			n++
+		}
+	}
+	if n > 1 {
+		return errors.New("only one of static, operator and certManager TLS can be set")
+	}
+	if tp.CertManager != nil {
+		ref := tp.CertManager.IssuerRef
+		if len(ref.Name) == 0 {
+			return errors.New("certManager issuerRef name not set")
+		}
+		if len(ref.Kind) != 0 && ref.Kind != "Issuer" && ref.Kind != "ClusterIssuer" {
+			return errors.New("certManager issuerRef kind must be Issuer or ClusterIssuer")
+		}
+		return nil
+	}
+	if tp.Operator != nil {
+		if tp.Operator.CertificateValidityInDays < 0 || tp.Operator.RenewBeforeInDays < 0 {
+			return errors.New("operator TLS durations must not be negative")
+		}
+		if tp.Operator.RenewBefore() >= tp.Operator.CertificateValidity() {
+			return errors.New("operator TLS renewBeforeInDays must be less than certificateValidityInDays")
+		}
+		return nil
+	}
 	if tp.Static == nil {
 		return nil
 	}
@@ -57,6 +149,9 @@ func (tp *TLSPolicy) Validate() error {
 }
 
 func (tp *TLSPolicy) IsSecureClient() bool {
+	if tp.IsManaged() {
+		return true
+	}
 	if tp == nil || tp.Static == nil {
 		return false
 	}
@@ -64,8 +159,56 @@ func (tp *TLSPolicy) IsSecureClient() bool {
 }
 
 func (tp *TLSPolicy) IsSecurePeer() bool {
+	if tp.IsManaged() {
+		return true
+	}
 	if tp == nil || tp.Static == nil || tp.Static.Member == nil {
 		return false
 	}
 	return len(tp.Static.Member.PeerSecret) != 0
 }
+
+// IsOperatorManaged tells whether the operator issues the certificates of the cluster.
+func (tp *TLSPolicy) IsOperatorManaged() bool {
+	return tp != nil && tp.Operator != nil
+}
+
+// IsCertManagerManaged tells whether cert-manager issues the certificates of the cluster.
+func (tp *TLSPolicy) IsCertManagerManaged() bool {
+	return tp != nil && tp.CertManager != nil
+}
+
+// IsManaged tells whether the certificates of the cluster are issued by the operator or by cert-manager,
+// rather than provided by the user.
+func (tp *TLSPolicy) IsManaged() bool {
+	return tp.IsOperatorManaged() || tp.IsCertManagerManaged()
+}
+
+// CASecret returns the name of the secret holding the CA the operator issues certificates from.
+func (tp *TLSPolicy) CASecret(clusterName string) string {
+	return clusterName + "-ca-tls"
+}
+
+// PeerSecret returns the name of the secret with the certificates of the members for peer communication.
+func (tp *TLSPolicy) PeerSecret(clusterName string) string {
+	if tp.IsManaged() {
+		return clusterName + "-peer-tls"
+	}
+	return tp.Static.Member.PeerSecret
+}
+
+// ServerSecret returns the name of the secret with the certificates the members serve clients with.
+func (tp *TLSPolicy) ServerSecret(clusterName string) string {
+	if tp.IsManaged() {
+		return clusterName + "-server-tls"
+	}
+	return tp.Static.Member.ServerSecret
+}
+
+// OperatorSecret returns the name of the secret with the client certificates the operator uses.
+func (tp *TLSPolicy) OperatorSecret(clusterName string) string {
+	if tp.IsManaged() {
+		return clusterName + "-operator-tls"
+	}
+	return tp.Static.OperatorSecret
+}
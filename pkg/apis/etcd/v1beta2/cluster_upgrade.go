@@ -0,0 +1,42 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UpgradePolicy defines how the operator upgrades an etcd cluster to a new version.
+type UpgradePolicy struct {
+	// BackupBeforeUpgrade makes the operator take a snapshot of the cluster before it upgrades the first member,
+	// the upgrade waits until the snapshot is saved. The snapshot is saved with the storage of Backup,
+	// in the directory "pre-upgrade" next to its path, so that it is not pruned with the periodic backups.
+	// Its path is recorded in status.upgradeBackup and in the Upgrading condition.
+	//
+	// Requires Backup to be set, with a storage type other than Local.
+	BackupBeforeUpgrade bool `json:"backupBeforeUpgrade,omitempty"`
+}
+
+// UpgradeBackup is a snapshot taken before an upgrade, to roll the upgrade back.
+type UpgradeBackup struct {
+	// FromVersion is the version of the cluster the snapshot was taken of.
+	FromVersion string `json:"fromVersion"`
+	// TargetVersion is the version the cluster is upgraded to after the snapshot.
+	TargetVersion string `json:"targetVersion"`
+	// Path is the path the snapshot is saved at, in the storage of spec.backup.
+	Path string `json:"path"`
+	// Time is when the snapshot was taken.
+	Time metav1.Time `json:"time"`
+}
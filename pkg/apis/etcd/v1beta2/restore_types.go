@@ -14,7 +14,42 @@
 
 package v1beta2
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	"errors"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RestorePhase is the step a restore is in, or how it finished.
+type RestorePhase string
+
+// RestoreConditionType is the type of a condition of a restore.
+type RestoreConditionType string
+
+const (
+	RestorePhaseNone RestorePhase = ""
+	// RestorePhaseValidating checks the spec, the reference EtcdCluster and the backup.
+	RestorePhaseValidating RestorePhase = "Validating"
+	// RestorePhaseDeleting deletes the reference EtcdCluster, unless a target is set.
+	RestorePhaseDeleting RestorePhase = "Deleting"
+	// RestorePhaseFetching waits for the seed member of the restored EtcdCluster to fetch the backup.
+	RestorePhaseFetching RestorePhase = "Fetching"
+	// RestorePhaseSeeding waits for the seed member to restore the backup and become ready.
+	RestorePhaseSeeding RestorePhase = "Seeding"
+	// RestorePhaseScaling waits for the etcd operator to scale the restored EtcdCluster to its size.
+	RestorePhaseScaling   RestorePhase = "Scaling"
+	RestorePhaseCompleted RestorePhase = "Completed"
+	RestorePhaseFailed    RestorePhase = "Failed"
+
+	// RestoreConditionProgressing is true while the restore is in progress, with the phase as its reason.
+	RestoreConditionProgressing RestoreConditionType = "Progressing"
+	// RestoreConditionComplete is true once the restored EtcdCluster reached its size.
+	RestoreConditionComplete RestoreConditionType = "Complete"
+	// RestoreConditionFailed is true once the restore failed, with the error as its message.
+	RestoreConditionFailed RestoreConditionType = "Failed"
+)
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
@@ -26,10 +61,14 @@ type EtcdRestoreList struct {
 }
 
 // +genclient
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+// +kubebuilder:printcolumn:name="Revision",type=integer,JSONPath=`.status.etcdRevision`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
 // EtcdRestore represents a Kubernetes EtcdRestore Custom Resource.
-// The EtcdRestore CR name will be used as the name of the new restored cluster.
+// Without a target, the EtcdRestore CR is named after the reference EtcdCluster, which is restored in place.
 type EtcdRestore struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata"`
@@ -37,6 +76,50 @@ type EtcdRestore struct {
 	Status            RestoreStatus `json:"status,omitempty"`
 }
 
+// Validate checks that the restore operator can handle the EtcdRestore.
+func (er *EtcdRestore) Validate() error {
+	if len(er.Spec.EtcdCluster.Name) == 0 {
+		return errors.New("spec.etcdCluster.name should not be empty")
+	}
+	if t := er.Spec.Target; t != nil {
+		if len(t.Name) == 0 {
+			return errors.New("spec.target.name should not be empty")
+		}
+		if t.Name == er.Spec.EtcdCluster.Name && (len(t.Namespace) == 0 || t.Namespace == er.Namespace) {
+			return fmt.Errorf("spec.target(%v) must not be the reference EtcdCluster", t.Name)
+		}
+	}
+	// NOTE: The seed member looks up the EtcdRestore CR by its own name and namespace, any name works for that.
+	// A restore in place is still named after the EtcdCluster it replaces, so that there is one per cluster.
+	if er.Spec.Target == nil && er.Name != er.Spec.EtcdCluster.Name {
+		return fmt.Errorf("EtcdRestore CR name(%v) must be the same as EtcdCluster name(%v)", er.Name, er.Spec.EtcdCluster.Name)
+	}
+	if err := er.Spec.Selector.Validate(); err != nil {
+		return err
+	}
+	if er.Spec.Direct && er.Spec.BackupStorageType == BackupStorageTypeLocal {
+		return errors.New("spec.direct does not support the Local backup storage type")
+	}
+	// The seed member mounts the secrets of the RestoreSource and Encryption, which exist in the namespace of er.
+	if ns, _ := er.RestoredCluster(); er.Spec.Direct && ns != er.Namespace {
+		return fmt.Errorf("spec.direct does not support a spec.target in another namespace(%v)", ns)
+	}
+	return er.Spec.Encryption.Validate()
+}
+
+// RestoredCluster returns the namespace and name of the EtcdCluster the backup is restored into.
+// That is the reference EtcdCluster, unless a target is set.
+func (er *EtcdRestore) RestoredCluster() (namespace, name string) {
+	t := er.Spec.Target
+	if t == nil {
+		return er.Namespace, er.Spec.EtcdCluster.Name
+	}
+	if len(t.Namespace) == 0 {
+		return er.Namespace, t.Name
+	}
+	return t.Namespace, t.Name
+}
+
 // RestoreSpec defines how to restore an etcd cluster from existing backup.
 type RestoreSpec struct {
 	// BackupStorageType is the type of the backup storage which is used as RestoreSource.
@@ -46,8 +129,22 @@ type RestoreSpec struct {
 	// EtcdCluster references an EtcdCluster resource whose metadata and spec
 	// will be used to create the new restored EtcdCluster CR.
 	// This reference EtcdCluster CR and all its resources will be deleted before the
-	// restored EtcdCluster CR is created.
+	// restored EtcdCluster CR is created, unless a Target is set.
 	EtcdCluster EtcdClusterRef `json:"etcdCluster"`
+	// Target restores the backup into a new EtcdCluster with the spec of the reference EtcdCluster,
+	// and leaves the reference EtcdCluster running.
+	Target *RestoreTarget `json:"target,omitempty"`
+	// Selector selects the backup to restore among the backups whose path starts with
+	// the path of the RestoreSource, instead of restoring the backup at that exact path.
+	Selector *RestoreSelector `json:"selector,omitempty"`
+	// Encryption references the key the backup is encrypted with.
+	// It must be set to restore a backup taken with encryption.
+	Encryption *BackupEncryption `json:"encryption,omitempty"`
+	// Direct makes the seed member read the backup from the backup storage itself, with the image of
+	// the restore operator, instead of downloading it from the restore operator.
+	// The seed member mounts the secrets of the RestoreSource and Encryption, so the backup can only be restored
+	// into the namespace of the EtcdRestore. The Local backup storage type is not supported.
+	Direct bool `json:"direct,omitempty"`
 }
 
 // EtcdCluster references an EtcdCluster resource whose metadata and spec
@@ -60,6 +157,59 @@ type EtcdClusterRef struct {
 	Name string `json:"name"`
 }
 
+// RestoreTarget is the new EtcdCluster a backup is restored into.
+type RestoreTarget struct {
+	// Name is the name of the restored EtcdCluster. It must not exist yet.
+	Name string `json:"name"`
+	// Namespace is the namespace of the restored EtcdCluster.
+	// Defaults to the namespace of the EtcdRestore CR.
+	// The restore operator must run with -cluster-wide to restore into another namespace.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// RestoreSelector selects a backup to restore. Exactly one of its fields must be set.
+// Latest, Before and EtcdRevision select among the backups in the format of periodic backups,
+// "<base path>_v<etcd store revision>_YYYY-MM-DD-HH:mm:SS".
+type RestoreSelector struct {
+	// Latest selects the newest backup.
+	Latest bool `json:"latest,omitempty"`
+	// Before selects the newest backup taken before this time.
+	Before *metav1.Time `json:"before,omitempty"`
+	// EtcdRevision selects the newest backup taken at this revision of etcd's KV store.
+	EtcdRevision int64 `json:"etcdRevision,omitempty"`
+	// EtcdBackup selects the backup last saved by the EtcdBackup with this name,
+	// in the namespace of the EtcdRestore CR.
+	// The EtcdBackup must save its backups in the same backup storage.
+	EtcdBackup string `json:"etcdBackup,omitempty"`
+}
+
+// Validate checks that exactly one selector is set. A nil RestoreSelector is valid.
+func (s *RestoreSelector) Validate() error {
+	if s == nil {
+		return nil
+	}
+	n := 0
+	if s.Latest {
+		n++
+	}
+	if s.Before != nil {
+		n++
+	}
+	if s.EtcdRevision != 0 {
+		n++
+	}
+	if len(s.EtcdBackup) != 0 {
+		n++
+	}
+	if n != 1 {
+		return errors.New("spec.selector must set exactly one of latest, before, etcdRevision and etcdBackup")
+	}
+	if s.EtcdRevision < 0 {
+		return errors.New("spec.selector.etcdRevision must be positive")
+	}
+	return nil
+}
+
 type RestoreSource struct {
 	// S3 tells where on S3 the backup is saved and how to fetch the backup.
 	S3 *S3RestoreSource `json:"s3,omitempty"`
@@ -72,6 +222,42 @@ type RestoreSource struct {
 
 	// OSS tells where on OSS the backup is saved and how to fetch the backup.
 	OSS *OSSRestoreSource `json:"oss,omitempty"`
+
+	// Local tells where on the volume mounted into the restore operator the backup is saved.
+	Local *LocalRestoreSource `json:"local,omitempty"`
+}
+
+// Secret returns the name of the secret with the credentials of the source of type st, if any.
+func (rs *RestoreSource) Secret(st BackupStorageType) string {
+	switch {
+	case st == BackupStorageTypeS3 && rs.S3 != nil:
+		return rs.S3.AWSSecret
+	case st == BackupStorageTypeABS && rs.ABS != nil:
+		return rs.ABS.ABSSecret
+	case st == BackupStorageTypeGCS && rs.GCS != nil:
+		return rs.GCS.GCPSecret
+	case st == BackupStorageTypeOSS && rs.OSS != nil:
+		return rs.OSS.OSSSecret
+	}
+	return ""
+}
+
+// WithPath returns a copy of the source of type st, with the path of the backup set to path.
+func (rs *RestoreSource) WithPath(st BackupStorageType, path string) *RestoreSource {
+	out := rs.DeepCopy()
+	switch {
+	case st == BackupStorageTypeS3 && out.S3 != nil:
+		out.S3.Path = path
+	case st == BackupStorageTypeABS && out.ABS != nil:
+		out.ABS.Path = path
+	case st == BackupStorageTypeGCS && out.GCS != nil:
+		out.GCS.Path = path
+	case st == BackupStorageTypeOSS && out.OSS != nil:
+		out.OSS.Path = path
+	case st == BackupStorageTypeLocal && out.Local != nil:
+		out.Local.Path = path
+	}
+	return out
 }
 
 type S3RestoreSource struct {
@@ -95,7 +281,7 @@ type S3RestoreSource struct {
 	// ForcePathStyle forces to use path style over the default subdomain style.
 	// This is useful when you have an s3 compatible endpoint that doesn't support
 	// subdomain buckets.
-	ForcePathStyle bool `json:"forcePathStyle"`
+	ForcePathStyle bool `json:"forcePathStyle,omitempty"`
 }
 
 type ABSRestoreSource struct {
@@ -157,10 +343,116 @@ type OSSRestoreSource struct {
 	Endpoint string `json:"endpoint,omitempty"`
 }
 
+type LocalRestoreSource struct {
+	// Path is the path of the backup file relative to the mount directory of the volume, "/var/etcd-backup".
+	// e.g: "mycluster/etcd.backup"
+	Path string `json:"path"`
+}
+
 // RestoreStatus reports the status of this restore operation.
 type RestoreStatus struct {
 	// Succeeded indicates if the backup has Succeeded.
 	Succeeded bool `json:"succeeded"`
 	// Reason indicates the reason for any backup related failures.
+	// A backup that does not match the checksum of its manifest is refused, and reported here.
+	Reason string `json:"reason,omitempty"`
+	// BackupSHA256 is the SHA-256 checksum of the backup, if it was verified against its manifest.
+	BackupSHA256 string `json:"backupSHA256,omitempty"`
+	// BackupPath is the path of the restored backup in the backup storage.
+	// With a selector, it is the backup that was selected.
+	BackupPath string `json:"backupPath,omitempty"`
+	// EtcdRevision is the revision of etcd's KV store the restored backup was taken at, if it is known.
+	EtcdRevision int64 `json:"etcdRevision,omitempty"`
+
+	// Phase is the step the restore is in, or Completed or Failed once it is finished.
+	// The restore only completes once the restored EtcdCluster reached its size.
+	Phase RestorePhase `json:"phase,omitempty"`
+	// Conditions are the Progressing, Complete and Failed conditions of the restore.
+	Conditions []RestoreCondition `json:"conditions,omitempty"`
+	// StartTime is when the restore operator started the restore.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+	// CompletionTime is when the restore completed or failed.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// RestoreCondition represents one current condition of a restore.
+type RestoreCondition struct {
+	// Type of restore condition.
+	Type RestoreConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status v1.ConditionStatus `json:"status"`
+	// The last time this condition was updated, for the Progressing condition when the phase last changed.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// The reason for the condition's last transition.
 	Reason string `json:"reason,omitempty"`
+	// A human readable message indicating details about the transition.
+	Message string `json:"message,omitempty"`
+}
+
+// IsFinished tells if the restore completed or failed. The restore operator does not touch a finished restore.
+// Restores of older restore operators have no phase, they are finished once they succeeded or have a reason.
+func (rs *RestoreStatus) IsFinished() bool {
+	return rs.Phase == RestorePhaseCompleted || rs.Phase == RestorePhaseFailed || rs.Succeeded || len(rs.Reason) != 0
+}
+
+// SetPhase moves the restore to the phase p in progress, and describes what it waits for in msg.
+func (rs *RestoreStatus) SetPhase(p RestorePhase, msg string) {
+	now := metav1.Now()
+	if rs.StartTime == nil {
+		rs.StartTime = &now
+	}
+	rs.Phase = p
+	rs.setCondition(RestoreConditionProgressing, v1.ConditionTrue, string(p), msg, now)
+}
+
+// SetCompleted marks the restore as completed.
+func (rs *RestoreStatus) SetCompleted(msg string) {
+	now := metav1.Now()
+	rs.Phase = RestorePhaseCompleted
+	rs.Succeeded = true
+	rs.CompletionTime = &now
+	rs.setCondition(RestoreConditionProgressing, v1.ConditionFalse, string(RestorePhaseCompleted), msg, now)
+	rs.setCondition(RestoreConditionComplete, v1.ConditionTrue, string(RestorePhaseCompleted), msg, now)
+}
+
+// SetFailed marks the restore as failed in its phase with err, which is also reported as the reason of the restore.
+func (rs *RestoreStatus) SetFailed(err error) {
+	now := metav1.Now()
+	reason := "Failed"
+	if rs.Phase != RestorePhaseNone {
+		reason = string(rs.Phase) + "Failed"
+	}
+	rs.Phase = RestorePhaseFailed
+	rs.Succeeded = false
+	rs.Reason = err.Error()
+	rs.CompletionTime = &now
+	rs.setCondition(RestoreConditionProgressing, v1.ConditionFalse, reason, rs.Reason, now)
+	rs.setCondition(RestoreConditionFailed, v1.ConditionTrue, reason, rs.Reason, now)
+}
+
+// Condition returns the condition of type t, or nil if the restore does not have it.
+func (rs *RestoreStatus) Condition(t RestoreConditionType) *RestoreCondition {
+	for i := range rs.Conditions {
+		if rs.Conditions[i].Type == t {
+			return &rs.Conditions[i]
+		}
+	}
+	return nil
+}
+
+func (rs *RestoreStatus) setCondition(t RestoreConditionType, status v1.ConditionStatus, reason, msg string, now metav1.Time) {
+	c := rs.Condition(t)
+	if c == nil {
+		rs.Conditions = append(rs.Conditions, RestoreCondition{Type: t})
+		c = &rs.Conditions[len(rs.Conditions)-1]
+	}
+	if c.Status != status {
+		c.LastTransitionTime = now
+	}
+	if c.Status != status || c.Reason != reason {
+		c.LastUpdateTime = now
+	}
+	c.Status, c.Reason, c.Message = status, reason, msg
 }
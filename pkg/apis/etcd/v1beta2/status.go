@@ -19,9 +19,13 @@ import (
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// ClusterPhase is the lifecycle phase of an etcd cluster.
+// +kubebuilder:validation:Enum="";Creating;Running;Failed
 type ClusterPhase string
+
 type ClusterConditionType string
 
 const (
@@ -35,6 +39,7 @@ const (
 	ClusterConditionRecovering                      = "Recovering"
 	ClusterConditionScaling                         = "Scaling"
 	ClusterConditionUpgrading                       = "Upgrading"
+	ClusterConditionRolling                         = "Rolling"
 )
 
 type ClusterStatus struct {
@@ -45,6 +50,10 @@ type ClusterStatus struct {
 	// ControlPuased indicates the operator pauses the control of the cluster.
 	ControlPaused bool `json:"controlPaused,omitempty"`
 
+	// ObservedGeneration is the generation of the spec the cluster was last reconciled to.
+	// The cluster matches the spec once it is equal to metadata.generation.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
 	// Condition keeps track of all cluster conditions, if they exist.
 	Conditions []ClusterCondition `json:"conditions,omitempty"`
 
@@ -68,6 +77,23 @@ type ClusterStatus struct {
 	// TargetVersion is the version the cluster upgrading to.
 	// If the cluster is not upgrading, TargetVersion is empty.
 	TargetVersion string `json:"targetVersion"`
+
+	// TLS is the status of the certificates issued by the operator.
+	// It is only set if the operator manages the TLS certificates of the cluster.
+	TLS *TLSStatus `json:"tls,omitempty"`
+
+	// UpgradeBackup is the snapshot taken before the last upgrade, if the upgrade policy asks for one.
+	UpgradeBackup *UpgradeBackup `json:"upgradeBackup,omitempty"`
+}
+
+// TLSStatus represents the certificates the operator issued for the cluster.
+type TLSStatus struct {
+	// CAExpirationDate is when the CA certificate expires.
+	CAExpirationDate metav1.Time `json:"caExpirationDate,omitempty"`
+	// ExpirationDate is when the first of the peer, server and operator client certificates expires.
+	ExpirationDate metav1.Time `json:"expirationDate,omitempty"`
+	// RenewalDate is when the operator renews the certificates.
+	RenewalDate metav1.Time `json:"renewalDate,omitempty"`
 }
 
 // ClusterCondition represents one current condition of an etcd cluster.
@@ -140,18 +166,54 @@ func (cs *ClusterStatus) SetScalingDownCondition(from, to int) {
 	cs.setClusterCondition(*c)
 }
 
-func (cs *ClusterStatus) SetRecoveringCondition() {
+func (cs *ClusterStatus) SetRecoveringCondition(from string) {
 	c := newClusterCondition(ClusterConditionRecovering, v1.ConditionTrue,
-		"Disaster recovery", "Majority is down. Recovering from backup")
+		"Disaster recovery", "Majority is down. Recovering from "+from)
 	cs.setClusterCondition(*c)
 
 	cs.ClearCondition(ClusterConditionAvailable)
 }
 
+// SetRecoveryFailedCondition marks the disaster recovery of the cluster as failed with msg.
+func (cs *ClusterStatus) SetRecoveryFailedCondition(msg string) {
+	c := newClusterCondition(ClusterConditionRecovering, v1.ConditionFalse,
+		"Disaster recovery failed", msg)
+	cs.setClusterCondition(*c)
+}
+
 func (cs *ClusterStatus) SetUpgradingCondition(to string) {
 	// TODO: show x/y members has upgraded.
 	c := newClusterCondition(ClusterConditionUpgrading, v1.ConditionTrue,
-		"Cluster upgrading", "upgrading to "+to)
+		"Cluster upgrading", cs.upgradeMsg("upgrading", to))
+	cs.setClusterCondition(*c)
+}
+
+// SetDowngradingCondition marks the cluster as downgrading to the previous minor version to.
+func (cs *ClusterStatus) SetDowngradingCondition(to string) {
+	c := newClusterCondition(ClusterConditionUpgrading, v1.ConditionTrue,
+		"Cluster downgrading", cs.upgradeMsg("downgrading", to))
+	cs.setClusterCondition(*c)
+}
+
+// SetUpgradeRejectedCondition marks the change of the cluster to another version as not supported,
+// the members keep their version.
+func (cs *ClusterStatus) SetUpgradeRejectedCondition(msg string) {
+	c := newClusterCondition(ClusterConditionUpgrading, v1.ConditionFalse,
+		"Version change not supported", msg)
+	cs.setClusterCondition(*c)
+}
+
+func (cs *ClusterStatus) upgradeMsg(action, to string) string {
+	msg := action + " to " + to
+	if ub := cs.UpgradeBackup; ub != nil && ub.TargetVersion == to {
+		msg += ", snapshot of " + ub.FromVersion + " saved at " + ub.Path
+	}
+	return msg
+}
+
+func (cs *ClusterStatus) SetRollingCondition(updated, total int) {
+	c := newClusterCondition(ClusterConditionRolling, v1.ConditionTrue,
+		"Replacing outdated members", fmt.Sprintf("%d of %d members are up to date", updated, total))
 	cs.setClusterCondition(*c)
 }
 
@@ -58,9 +58,48 @@ func (in *ABSRestoreSource) DeepCopy() *ABSRestoreSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupAttempt) DeepCopyInto(out *BackupAttempt) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	out.Duration = in.Duration
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupAttempt.
+func (in *BackupAttempt) DeepCopy() *BackupAttempt {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupAttempt)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupEncryption) DeepCopyInto(out *BackupEncryption) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupEncryption.
+func (in *BackupEncryption) DeepCopy() *BackupEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupPolicy) DeepCopyInto(out *BackupPolicy) {
 	*out = *in
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(BackupRetention)
+		**out = **in
+	}
 	return
 }
 
@@ -74,6 +113,22 @@ func (in *BackupPolicy) DeepCopy() *BackupPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRetention) DeepCopyInto(out *BackupRetention) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRetention.
+func (in *BackupRetention) DeepCopy() *BackupRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupSource) DeepCopyInto(out *BackupSource) {
 	*out = *in
@@ -97,6 +152,11 @@ func (in *BackupSource) DeepCopyInto(out *BackupSource) {
 		*out = new(OSSBackupSource)
 		**out = **in
 	}
+	if in.Local != nil {
+		in, out := &in.Local, &out.Local
+		*out = new(LocalBackupSource)
+		**out = **in
+	}
 	return
 }
 
@@ -118,12 +178,22 @@ func (in *BackupSpec) DeepCopyInto(out *BackupSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.EtcdClusterRef != nil {
+		in, out := &in.EtcdClusterRef, &out.EtcdClusterRef
+		*out = new(EtcdClusterRef)
+		**out = **in
+	}
 	if in.BackupPolicy != nil {
 		in, out := &in.BackupPolicy, &out.BackupPolicy
 		*out = new(BackupPolicy)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	in.BackupSource.DeepCopyInto(&out.BackupSource)
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(BackupEncryption)
+		**out = **in
+	}
 	return
 }
 
@@ -142,6 +212,15 @@ func (in *BackupStatus) DeepCopyInto(out *BackupStatus) {
 	*out = *in
 	in.LastSuccessDate.DeepCopyInto(&out.LastSuccessDate)
 	in.LastExecutionDate.DeepCopyInto(&out.LastExecutionDate)
+	in.NextScheduledDate.DeepCopyInto(&out.NextScheduledDate)
+	in.LastFailureDate.DeepCopyInto(&out.LastFailureDate)
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]BackupAttempt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -155,6 +234,66 @@ func (in *BackupStatus) DeepCopy() *BackupStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerIssuerRef) DeepCopyInto(out *CertManagerIssuerRef) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerIssuerRef.
+func (in *CertManagerIssuerRef) DeepCopy() *CertManagerIssuerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerIssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertManagerTLS) DeepCopyInto(out *CertManagerTLS) {
+	*out = *in
+	out.IssuerRef = in.IssuerRef
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerTLS.
+func (in *CertManagerTLS) DeepCopy() *CertManagerTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(CertManagerTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterBackupPolicy) DeepCopyInto(out *ClusterBackupPolicy) {
+	*out = *in
+	if in.BackupPolicy != nil {
+		in, out := &in.BackupPolicy, &out.BackupPolicy
+		*out = new(BackupPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	in.BackupSource.DeepCopyInto(&out.BackupSource)
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(BackupEncryption)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterBackupPolicy.
+func (in *ClusterBackupPolicy) DeepCopy() *ClusterBackupPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterBackupPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterCondition) DeepCopyInto(out *ClusterCondition) {
 	*out = *in
@@ -189,6 +328,21 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 		*out = new(TLSPolicy)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Recovery != nil {
+		in, out := &in.Recovery, &out.Recovery
+		*out = new(RecoveryPolicy)
+		**out = **in
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(ClusterBackupPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpgradePolicy != nil {
+		in, out := &in.UpgradePolicy, &out.UpgradePolicy
+		*out = new(UpgradePolicy)
+		**out = **in
+	}
 	return
 }
 
@@ -211,6 +365,16 @@ func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 		copy(*out, *in)
 	}
 	in.Members.DeepCopyInto(&out.Members)
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(TLSStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpgradeBackup != nil {
+		in, out := &in.UpgradeBackup, &out.UpgradeBackup
+		*out = new(UpgradeBackup)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -368,7 +532,7 @@ func (in *EtcdRestore) DeepCopyInto(out *EtcdRestore) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -455,6 +619,38 @@ func (in *GCSRestoreSource) DeepCopy() *GCSRestoreSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalBackupSource) DeepCopyInto(out *LocalBackupSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalBackupSource.
+func (in *LocalBackupSource) DeepCopy() *LocalBackupSource {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalBackupSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalRestoreSource) DeepCopyInto(out *LocalRestoreSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalRestoreSource.
+func (in *LocalRestoreSource) DeepCopy() *LocalRestoreSource {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalRestoreSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MemberSecret) DeepCopyInto(out *MemberSecret) {
 	*out = *in
@@ -529,6 +725,22 @@ func (in *OSSRestoreSource) DeepCopy() *OSSRestoreSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorTLS) DeepCopyInto(out *OperatorTLS) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorTLS.
+func (in *OperatorTLS) DeepCopy() *OperatorTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodPolicy) DeepCopyInto(out *PodPolicy) {
 	*out = *in
@@ -596,6 +808,60 @@ func (in *PodPolicy) DeepCopy() *PodPolicy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryPolicy) DeepCopyInto(out *RecoveryPolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryPolicy.
+func (in *RecoveryPolicy) DeepCopy() *RecoveryPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreCondition) DeepCopyInto(out *RestoreCondition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreCondition.
+func (in *RestoreCondition) DeepCopy() *RestoreCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreSelector) DeepCopyInto(out *RestoreSelector) {
+	*out = *in
+	if in.Before != nil {
+		in, out := &in.Before, &out.Before
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreSelector.
+func (in *RestoreSelector) DeepCopy() *RestoreSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RestoreSource) DeepCopyInto(out *RestoreSource) {
 	*out = *in
@@ -619,6 +885,11 @@ func (in *RestoreSource) DeepCopyInto(out *RestoreSource) {
 		*out = new(OSSRestoreSource)
 		**out = **in
 	}
+	if in.Local != nil {
+		in, out := &in.Local, &out.Local
+		*out = new(LocalRestoreSource)
+		**out = **in
+	}
 	return
 }
 
@@ -637,6 +908,21 @@ func (in *RestoreSpec) DeepCopyInto(out *RestoreSpec) {
 	*out = *in
 	in.RestoreSource.DeepCopyInto(&out.RestoreSource)
 	out.EtcdCluster = in.EtcdCluster
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(RestoreTarget)
+		**out = **in
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(RestoreSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(BackupEncryption)
+		**out = **in
+	}
 	return
 }
 
@@ -653,6 +939,21 @@ func (in *RestoreSpec) DeepCopy() *RestoreSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RestoreStatus) DeepCopyInto(out *RestoreStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]RestoreCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -666,6 +967,22 @@ func (in *RestoreStatus) DeepCopy() *RestoreStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreTarget) DeepCopyInto(out *RestoreTarget) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreTarget.
+func (in *RestoreTarget) DeepCopy() *RestoreTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *S3BackupSource) DeepCopyInto(out *S3BackupSource) {
 	*out = *in
@@ -757,6 +1074,16 @@ func (in *TLSPolicy) DeepCopyInto(out *TLSPolicy) {
 		*out = new(StaticTLS)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Operator != nil {
+		in, out := &in.Operator, &out.Operator
+		*out = new(OperatorTLS)
+		**out = **in
+	}
+	if in.CertManager != nil {
+		in, out := &in.CertManager, &out.CertManager
+		*out = new(CertManagerTLS)
+		**out = **in
+	}
 	return
 }
 
@@ -769,3 +1096,55 @@ func (in *TLSPolicy) DeepCopy() *TLSPolicy {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSStatus) DeepCopyInto(out *TLSStatus) {
+	*out = *in
+	in.CAExpirationDate.DeepCopyInto(&out.CAExpirationDate)
+	in.ExpirationDate.DeepCopyInto(&out.ExpirationDate)
+	in.RenewalDate.DeepCopyInto(&out.RenewalDate)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSStatus.
+func (in *TLSStatus) DeepCopy() *TLSStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeBackup) DeepCopyInto(out *UpgradeBackup) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeBackup.
+func (in *UpgradeBackup) DeepCopy() *UpgradeBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradePolicy) DeepCopyInto(out *UpgradePolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradePolicy.
+func (in *UpgradePolicy) DeepCopy() *UpgradePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
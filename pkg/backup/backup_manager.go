@@ -15,19 +15,22 @@
 package backup
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"os"
 	"sort"
 	"time"
 
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
 	"github.com/on2itsecurity/etcd-operator/pkg/backup/util"
 	"github.com/on2itsecurity/etcd-operator/pkg/backup/writer"
 	"github.com/on2itsecurity/etcd-operator/pkg/util/constants"
 	clientv3 "go.etcd.io/etcd/client/v3"
 
 	"github.com/sirupsen/logrus"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -37,60 +40,108 @@ type BackupManager struct {
 
 	endpoints     []string
 	namespace     string
+	clusterName   string
+	clusterUID    string
 	etcdTLSConfig *tls.Config
+	compression   api.BackupCompression
 
 	bw writer.Writer
 }
 
 // NewBackupManagerFromWriter creates a BackupManager with backup writer.
-func NewBackupManagerFromWriter(kubecli kubernetes.Interface, bw writer.Writer, tc *tls.Config, endpoints []string, namespace string) *BackupManager {
+// clusterName and clusterUID are recorded in the manifests of the snapshots, they may be empty if unknown.
+// Snapshots are compressed with codec c, if set.
+func NewBackupManagerFromWriter(kubecli kubernetes.Interface, bw writer.Writer, tc *tls.Config, endpoints []string, namespace, clusterName, clusterUID string,
+	c api.BackupCompression) *BackupManager {
 	return &BackupManager{
 		kubecli:       kubecli,
 		endpoints:     endpoints,
 		namespace:     namespace,
+		clusterName:   clusterName,
+		clusterUID:    clusterUID,
 		etcdTLSConfig: tc,
+		compression:   c,
 		bw:            bw,
 	}
 }
 
 // SaveSnap uses backup writer to save etcd snapshot to a specified S3 path
-// and returns backup etcd server's kv store revision and its version.
-func (bm *BackupManager) SaveSnap(ctx context.Context, s3Path string, isPeriodic bool) (int64, string, *metav1.Time, error) {
+// and returns the path of the snapshot, and its manifest with backup etcd server's kv store revision and its version.
+// The integrity hash etcd appends to the snapshot is checked before the snapshot is written,
+// and a Manifest is written next to it.
+// Periodic snapshots get the file name extension of the compression codec.
+func (bm *BackupManager) SaveSnap(ctx context.Context, s3Path string, isPeriodic bool) (string, *Manifest, error) {
 	now := time.Now().UTC()
 	etcdcli, rev, err := bm.etcdClientWithMaxRevision(ctx)
 	if err != nil {
-		return 0, "", nil, fmt.Errorf("create etcd client failed: %v", err)
+		return "", nil, fmt.Errorf("create etcd client failed: %v", err)
 	}
 	defer etcdcli.Close()
 
 	resp, err := etcdcli.Status(ctx, etcdcli.Endpoints()[0])
 	if err != nil {
-		return 0, "", nil, fmt.Errorf("failed to retrieve etcd version from the status call: %v", err)
+		return "", nil, fmt.Errorf("failed to retrieve etcd version from the status call: %v", err)
 	}
 
 	rc, err := etcdcli.Snapshot(ctx)
 	if err != nil {
-		return 0, "", nil, fmt.Errorf("failed to receive snapshot (%v)", err)
+		return "", nil, fmt.Errorf("failed to receive snapshot (%v)", err)
 	}
 	defer rc.Close()
+	f, size, sum, err := spoolSnapshot(rc)
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
 	if isPeriodic {
-		// NOTE: make sure this path format stays in sync with util.SortableBackupPaths
-		s3Path = fmt.Sprintf(s3Path+"_v%d_%s", rev, now.Format("2006-01-02-15:04:05"))
+		s3Path = util.PeriodicBackupPath(s3Path, rev, now) + bm.compression.Extension()
+	}
+	sw := bm.bw
+	if bm.compression != api.BackupCompressionNone {
+		sw = writer.NewCompressedWriter(bm.bw, bm.compression)
 	}
-	_, err = bm.bw.Write(ctx, s3Path, rc)
+	_, err = sw.Write(ctx, s3Path, f)
 	if err != nil {
-		return 0, "", nil, fmt.Errorf("failed to write snapshot (%v)", err)
+		return "", nil, fmt.Errorf("failed to write snapshot (%v)", err)
 	}
-	return rev, resp.Version, &metav1.Time{Time: now}, nil
+
+	m := &Manifest{
+		SHA256:       sum,
+		Size:         size,
+		EtcdRevision: rev,
+		EtcdVersion:  resp.Version,
+		ClusterName:  bm.clusterName,
+		ClusterUID:   bm.clusterUID,
+		ClusterID:    fmt.Sprintf("%x", resp.Header.ClusterId),
+		Timestamp:    now,
+		Compression:  bm.compression,
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return "", nil, err
+	}
+	_, err = bm.bw.Write(ctx, util.ManifestPath(s3Path), bytes.NewReader(b))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to write snapshot manifest (%v)", err)
+	}
+	return s3Path, m, nil
 }
 
 // EnsureMaxBackup to ensure the number of snapshot is under maxcount
 // if the number of snapshot exceeded than maxcount, delete oldest snapshot
 func (bm *BackupManager) EnsureMaxBackup(ctx context.Context, basePath string, maxCount int) error {
-	savedSnapShots, err := bm.bw.List(ctx, basePath)
+	paths, err := bm.bw.List(ctx, basePath)
 	if err != nil {
 		return fmt.Errorf("failed to get exisiting snapshots: %v", err)
 	}
+	savedSnapShots := []string{}
+	for _, p := range paths {
+		if !util.IsManifestPath(p) {
+			savedSnapShots = append(savedSnapShots, p)
+		}
+	}
 	sort.Sort(sort.Reverse(util.SortableBackupPaths(sort.StringSlice(savedSnapShots))))
 	for i, snapshotPath := range savedSnapShots {
 		if i < maxCount {
@@ -101,6 +152,10 @@ func (bm *BackupManager) EnsureMaxBackup(ctx context.Context, basePath string, m
 		if err != nil {
 			return fmt.Errorf("failed to delete snapshot: %v", err)
 		}
+		// Snapshots saved by older versions have no manifest.
+		if err := bm.bw.Delete(ctx, util.ManifestPath(snapshotPath)); err != nil {
+			logrus.Warningf("failed to delete manifest of snapshot %s: %v", snapshotPath, err)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,61 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/util"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/writer"
+)
+
+func TestEnsureMaxBackup(t *testing.T) {
+	ctx := context.Background()
+	for i, c := range []api.BackupCompression{api.BackupCompressionNone, api.BackupCompressionGzip, api.BackupCompressionZstd} {
+		w := writer.NewLocalWriter(t.TempDir())
+		bm := NewBackupManagerFromWriter(nil, w, nil, nil, "", "", "", c)
+
+		start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		var paths []string
+		for j := 0; j < 4; j++ {
+			p := util.PeriodicBackupPath("etcd.backup", int64(j), start.Add(time.Duration(j)*time.Minute)) + c.Extension()
+			paths = append(paths, p)
+			for _, name := range []string{p, util.ManifestPath(p)} {
+				if _, err := w.Write(ctx, name, strings.NewReader(name)); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		if err := bm.EnsureMaxBackup(ctx, "etcd.backup", 2); err != nil {
+			t.Fatal(err)
+		}
+		got, err := w.List(ctx, "etcd.backup")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sort.Strings(got)
+		want := []string{paths[2], util.ManifestPath(paths[2]), paths[3], util.ManifestPath(paths[3])}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("#%d: remaining get=%v, want=%v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compression compresses backups while they are streamed,
+// and detects the codec of compressed backups from their first bytes.
+package compression
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// NewCompressingReader returns a reader of the compression of r with codec c.
+// Close must be called once the reader is no longer read, to stop the compression.
+func NewCompressingReader(r io.Reader, c api.BackupCompression) (io.ReadCloser, error) {
+	pr, pw := io.Pipe()
+	var cw io.WriteCloser
+	switch c {
+	case api.BackupCompressionGzip:
+		cw = gzip.NewWriter(pw)
+	case api.BackupCompressionZstd:
+		zw, err := zstd.NewWriter(pw)
+		if err != nil {
+			return nil, err
+		}
+		cw = zw
+	default:
+		return nil, fmt.Errorf("unknown backup compression (%s)", c)
+	}
+	go func() {
+		_, err := io.Copy(cw, r)
+		if cerr := cw.Close(); err == nil {
+			err = cerr
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+// Detect returns the codec b, the start of a backup, is compressed with.
+func Detect(b []byte) api.BackupCompression {
+	switch {
+	case bytes.HasPrefix(b, gzipMagic):
+		return api.BackupCompressionGzip
+	case bytes.HasPrefix(b, zstdMagic):
+		return api.BackupCompressionZstd
+	}
+	return api.BackupCompressionNone
+}
+
+// NewDecompressingReader returns a reader of the decompression of r, with the codec it is compressed with.
+// r is read as is if it is not compressed. Close does not close r.
+func NewDecompressingReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	start, err := br.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	switch Detect(start) {
+	case api.BackupCompressionGzip:
+		return gzip.NewReader(br)
+	case api.BackupCompressionZstd:
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		return zr.IOReadCloser(), nil
+	}
+	return io.NopCloser(br), nil
+}
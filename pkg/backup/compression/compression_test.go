@@ -0,0 +1,80 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compression
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+)
+
+func TestRoundTrip(t *testing.T) {
+	plain := bytes.Repeat([]byte("etcd snapshot "), 10000)
+	for i, c := range []api.BackupCompression{api.BackupCompressionGzip, api.BackupCompressionZstd} {
+		cr, err := NewCompressingReader(bytes.NewReader(plain), c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		compressed, err := io.ReadAll(cr)
+		cr.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(compressed) >= len(plain) {
+			t.Errorf("#%d: compressed size %d is not smaller than %d", i, len(compressed), len(plain))
+		}
+		if got := Detect(compressed); got != c {
+			t.Errorf("#%d: detected codec get=%q, want=%q", i, got, c)
+		}
+
+		dr, err := NewDecompressingReader(bytes.NewReader(compressed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(dr)
+		dr.Close()
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		if !bytes.Equal(got, plain) {
+			t.Errorf("#%d: decompressed data does not match", i)
+		}
+	}
+}
+
+func TestDecompressUncompressed(t *testing.T) {
+	for i, plain := range [][]byte{{}, {0}, bytes.Repeat([]byte{0}, 4096)} {
+		dr, err := NewDecompressingReader(bytes.NewReader(plain))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(dr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, plain) {
+			t.Errorf("#%d: expected uncompressed data to be read as is", i)
+		}
+	}
+}
+
+func TestUnknownCodec(t *testing.T) {
+	if _, err := NewCompressingReader(bytes.NewReader(nil), "lz4"); err == nil {
+		t.Error("expected an error for an unknown codec")
+	}
+}
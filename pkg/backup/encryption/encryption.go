@@ -0,0 +1,243 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package encryption encrypts backups with AES-256-GCM, in chunks, so that they can be streamed.
+//
+// An encrypted backup starts with a header of the magic "ETCDENC1" and a random nonce prefix.
+// The plaintext follows in chunks of 64 KiB, each sealed with a nonce of the prefix, the chunk counter
+// and a flag marking the last chunk, so that chunks cannot be reordered, dropped or truncated unnoticed.
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// KeySize is the size of the AES-256 key.
+	KeySize = 32
+
+	// Magic is the start of every encrypted backup.
+	Magic = "ETCDENC1"
+
+	prefixSize  = 7
+	headerSize  = len(Magic) + prefixSize
+	chunkSize   = 64 * 1024
+	tagSize     = 16
+	lastChunk   = 1
+	counterSize = 4
+)
+
+// KeyFromSecret returns the encryption key referenced by e, from the secret in namespace.
+func KeyFromSecret(ctx context.Context, kubecli kubernetes.Interface, namespace string, e *api.BackupEncryption) ([]byte, error) {
+	secret, err := kubecli.CoreV1().Secrets(namespace).Get(ctx, e.Secret, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encryption key secret (%s): %v", e.Secret, err)
+	}
+	return KeyFromSecretData(secret.Data, e)
+}
+
+// KeyFromSecretData returns the key referenced by e from the data of its secret.
+func KeyFromSecretData(data map[string][]byte, e *api.BackupEncryption) ([]byte, error) {
+	key, ok := data[e.KeyOrDefault()]
+	if !ok {
+		return nil, fmt.Errorf("encryption key secret (%s) has no %s key", e.Secret, e.KeyOrDefault())
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key in secret (%s) has %d bytes, want %d", e.Secret, len(key), KeySize)
+	}
+	return key, nil
+}
+
+// IsEncrypted tells whether b, the start of a backup, is the start of an encrypted backup.
+func IsEncrypted(b []byte) bool {
+	return bytes.HasPrefix(b, []byte(Magic))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("encryption key has %d bytes, want %d", len(key), KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, prefixSize+counterSize+1)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[prefixSize:], counter)
+	if last {
+		nonce[len(nonce)-1] = lastChunk
+	}
+	return nonce
+}
+
+// NewEncryptingReader returns a reader of the encryption of r with key.
+func NewEncryptingReader(r io.Reader, key []byte) (io.Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, headerSize)
+	copy(header, Magic)
+	if _, err := rand.Read(header[len(Magic):]); err != nil {
+		return nil, err
+	}
+	return &encrypter{
+		src:    bufio.NewReaderSize(r, chunkSize),
+		aead:   aead,
+		header: header,
+		out:    header,
+		buf:    make([]byte, chunkSize+tagSize),
+	}, nil
+}
+
+type encrypter struct {
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	header  []byte
+	counter uint32
+	buf     []byte
+	// out is the encrypted data that is not read yet.
+	out  []byte
+	done bool
+}
+
+func (e *encrypter) Read(p []byte) (int, error) {
+	for len(e.out) == 0 {
+		if e.done {
+			return 0, io.EOF
+		}
+		if err := e.sealChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, e.out)
+	e.out = e.out[n:]
+	return n, nil
+}
+
+func (e *encrypter) sealChunk() error {
+	n, err := io.ReadFull(e.src, e.buf[:chunkSize])
+	last := false
+	switch err {
+	case nil:
+		if _, perr := e.src.Peek(1); perr == io.EOF {
+			last = true
+		} else if perr != nil {
+			return perr
+		}
+	case io.EOF, io.ErrUnexpectedEOF:
+		last = true
+	default:
+		return err
+	}
+	if e.counter == math.MaxUint32 {
+		return errors.New("backup is too large to encrypt")
+	}
+	e.out = e.aead.Seal(e.buf[:0], chunkNonce(e.header[len(Magic):], e.counter, last), e.buf[:n], e.header)
+	e.counter++
+	e.done = last
+	return nil
+}
+
+// NewDecryptingReader returns a reader of the decryption of r with key.
+// Reading fails if r was not encrypted with key, or was modified or truncated.
+func NewDecryptingReader(r io.Reader, key []byte) (io.Reader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &decrypter{
+		src:  bufio.NewReaderSize(r, chunkSize+tagSize),
+		aead: aead,
+		buf:  make([]byte, chunkSize+tagSize),
+	}, nil
+}
+
+type decrypter struct {
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	header  []byte
+	counter uint32
+	buf     []byte
+	// out is the decrypted data that is not read yet.
+	out  []byte
+	done bool
+}
+
+func (d *decrypter) Read(p []byte) (int, error) {
+	if d.header == nil {
+		header := make([]byte, headerSize)
+		if _, err := io.ReadFull(d.src, header); err != nil || !IsEncrypted(header) {
+			return 0, errors.New("backup is not encrypted")
+		}
+		d.header = header
+	}
+	for len(d.out) == 0 {
+		if d.done {
+			return 0, io.EOF
+		}
+		if err := d.openChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, d.out)
+	d.out = d.out[n:]
+	return n, nil
+}
+
+func (d *decrypter) openChunk() error {
+	n, err := io.ReadFull(d.src, d.buf)
+	last := false
+	switch err {
+	case nil:
+		if _, perr := d.src.Peek(1); perr == io.EOF {
+			last = true
+		} else if perr != nil {
+			return perr
+		}
+	case io.ErrUnexpectedEOF:
+		last = true
+	case io.EOF:
+		return errors.New("encrypted backup is truncated")
+	default:
+		return err
+	}
+	out, err := d.aead.Open(d.buf[:0], chunkNonce(d.header[len(Magic):], d.counter, last), d.buf[:n], d.header)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt backup, the key does not match or the backup is corrupted: %v", err)
+	}
+	d.out = out
+	d.counter++
+	d.done = last
+	return nil
+}
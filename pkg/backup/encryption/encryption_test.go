@@ -0,0 +1,97 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+)
+
+func encrypt(t *testing.T, plain, key []byte) []byte {
+	er, err := NewEncryptingReader(bytes.NewReader(plain), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(er)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func decrypt(b, key []byte) ([]byte, error) {
+	dr, err := NewDecryptingReader(bytes.NewReader(b), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(dr)
+}
+
+func TestRoundTrip(t *testing.T) {
+	key := make([]byte, KeySize)
+	rand.Read(key)
+	for i, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 100} {
+		plain := make([]byte, size)
+		rand.Read(plain)
+		b := encrypt(t, plain, key)
+		if !IsEncrypted(b) {
+			t.Errorf("#%d: expected encrypted backup to start with %q", i, Magic)
+		}
+		got, err := decrypt(b, key)
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		if !bytes.Equal(got, plain) {
+			t.Errorf("#%d: decrypted %d bytes do not match the %d bytes encrypted", i, len(got), size)
+		}
+	}
+}
+
+func TestDecryptFailures(t *testing.T) {
+	key := make([]byte, KeySize)
+	rand.Read(key)
+	otherKey := make([]byte, KeySize)
+	rand.Read(otherKey)
+	plain := make([]byte, 2*chunkSize+10)
+	rand.Read(plain)
+	b := encrypt(t, plain, key)
+	tampered := bytes.Clone(b)
+	tampered[headerSize+10] ^= 1
+	sealedChunk := chunkSize + tagSize
+
+	tests := []struct {
+		b   []byte
+		key []byte
+	}{
+		{b, otherKey},
+		{tampered, key},
+		// truncated at a chunk boundary
+		{b[:headerSize+sealedChunk], key},
+		{b[:headerSize+2*sealedChunk], key},
+		// truncated within a chunk
+		{b[:len(b)-1], key},
+		// the header only
+		{b[:headerSize], key},
+		{plain, key},
+	}
+	for i, tt := range tests {
+		if _, err := decrypt(tt.b, tt.key); err == nil {
+			t.Errorf("#%d: expected an error", i)
+		}
+	}
+}
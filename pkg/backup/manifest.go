@@ -0,0 +1,150 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"time"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/reader"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/util"
+)
+
+// Manifest describes a snapshot saved by the BackupManager.
+// It is saved next to the snapshot, at util.ManifestPath of the snapshot path.
+type Manifest struct {
+	// SHA256 is the hex encoded SHA-256 checksum of the snapshot.
+	SHA256 string `json:"sha256"`
+	// Size is the size of the snapshot in bytes.
+	Size int64 `json:"size"`
+	// EtcdRevision is the revision of etcd's KV store the snapshot was taken at.
+	EtcdRevision int64 `json:"etcdRevision"`
+	// EtcdVersion is the version of the etcd server the snapshot was taken from.
+	EtcdVersion string `json:"etcdVersion"`
+	// ClusterName is the name of the EtcdCluster the snapshot was taken of, if known.
+	ClusterName string `json:"clusterName,omitempty"`
+	// ClusterUID is the UID of the EtcdCluster resource the snapshot was taken of, if known.
+	ClusterUID string `json:"clusterUID,omitempty"`
+	// ClusterID is the ID etcd assigned to the cluster, hex encoded.
+	ClusterID string `json:"clusterID"`
+	// Timestamp is the time the snapshot was taken.
+	Timestamp time.Time `json:"timestamp"`
+	// Compression is the codec the snapshot is compressed with, empty if it is not compressed.
+	// SHA256 and Size are of the uncompressed snapshot.
+	Compression api.BackupCompression `json:"compression,omitempty"`
+}
+
+// ReadManifest reads the manifest of the snapshot at path.
+// The error wraps os.ErrNotExist if the snapshot has no manifest.
+func ReadManifest(r reader.Reader, path string) (*Manifest, error) {
+	rc, err := r.Open(util.ManifestPath(path))
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	m := &Manifest{}
+	if err := json.NewDecoder(rc).Decode(m); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest of %s: %v", path, err)
+	}
+	return m, nil
+}
+
+// NewVerifier returns a reader that reads the snapshot from r, and fails at the end
+// of the snapshot if it does not match the size and checksum of the manifest.
+func (m *Manifest) NewVerifier(r io.Reader) io.Reader {
+	return &verifier{r: r, m: m, h: sha256.New()}
+}
+
+type verifier struct {
+	r io.Reader
+	m *Manifest
+	h hash.Hash
+	n int64
+}
+
+func (v *verifier) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	v.h.Write(p[:n])
+	v.n += int64(n)
+	if err == io.EOF {
+		if v.n != v.m.Size {
+			return n, fmt.Errorf("snapshot size %d does not match the manifest size %d", v.n, v.m.Size)
+		}
+		if sum := hex.EncodeToString(v.h.Sum(nil)); sum != v.m.SHA256 {
+			return n, fmt.Errorf("snapshot checksum %s does not match the manifest checksum %s", sum, v.m.SHA256)
+		}
+	}
+	return n, err
+}
+
+// spoolSnapshot copies the snapshot from r to a temporary file, and checks the SHA-256 hash etcd appends to it.
+// It returns the file, positioned at the start, and the size and hex encoded SHA-256 checksum of the snapshot.
+// The caller must close and remove the file.
+func spoolSnapshot(r io.Reader) (*os.File, int64, string, error) {
+	f, err := os.CreateTemp("", "etcd-snapshot-*")
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("failed to create temporary snapshot file: %v", err)
+	}
+	fail := func(err error) (*os.File, int64, string, error) {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, 0, "", err
+	}
+
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(f, h), r)
+	if err != nil {
+		return fail(fmt.Errorf("failed to receive snapshot: %v", err))
+	}
+	if err := verifySnapshotHash(f, size); err != nil {
+		return fail(err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fail(err)
+	}
+	return f, size, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verifySnapshotHash checks the SHA-256 hash of the database etcd appends to a snapshot.
+func verifySnapshotHash(f *os.File, size int64) error {
+	// etcd pads the database to a multiple of 512 bytes before it appends the hash.
+	if size < sha256.Size || size%512 != sha256.Size {
+		return fmt.Errorf("snapshot of %d bytes has no integrity hash", size)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	h := sha256.New()
+	if _, err := io.CopyN(h, f, size-sha256.Size); err != nil {
+		return fmt.Errorf("failed to read snapshot: %v", err)
+	}
+	want := make([]byte, sha256.Size)
+	if _, err := io.ReadFull(f, want); err != nil {
+		return fmt.Errorf("failed to read snapshot: %v", err)
+	}
+	if !bytes.Equal(h.Sum(nil), want) {
+		return fmt.Errorf("snapshot integrity hash does not match its database")
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/reader"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/util"
+)
+
+// fakeSnapshot returns a snapshot of a database of size bytes, with the hash etcd appends to it.
+func fakeSnapshot(size int) []byte {
+	db := bytes.Repeat([]byte{'x'}, size)
+	h := sha256.Sum256(db)
+	return append(db, h[:]...)
+}
+
+func TestSpoolSnapshot(t *testing.T) {
+	corrupted := fakeSnapshot(4096)
+	corrupted[0] = 'y'
+
+	tests := []struct {
+		snap []byte
+		wErr bool
+	}{
+		{snap: fakeSnapshot(4096)},
+		{snap: corrupted, wErr: true},
+		// no hash appended
+		{snap: bytes.Repeat([]byte{'x'}, 4096), wErr: true},
+		{snap: nil, wErr: true},
+	}
+	for i, tt := range tests {
+		f, size, sum, err := spoolSnapshot(bytes.NewReader(tt.snap))
+		if (err != nil) != tt.wErr {
+			t.Errorf("#%d: err get=%v, want err=%v", i, err, tt.wErr)
+			continue
+		}
+		if tt.wErr {
+			continue
+		}
+		b, err := io.ReadAll(f)
+		f.Close()
+		os.Remove(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+		h := sha256.Sum256(tt.snap)
+		if !bytes.Equal(b, tt.snap) || size != int64(len(tt.snap)) || sum != hex.EncodeToString(h[:]) {
+			t.Errorf("#%d: spooled snapshot does not match the snapshot", i)
+		}
+	}
+}
+
+func TestManifestVerifier(t *testing.T) {
+	snap := fakeSnapshot(512)
+	h := sha256.Sum256(snap)
+	m := &Manifest{SHA256: hex.EncodeToString(h[:]), Size: int64(len(snap))}
+
+	tests := []struct {
+		snap []byte
+		wErr bool
+	}{
+		{snap: snap},
+		{snap: snap[:len(snap)-1], wErr: true},
+		{snap: append([]byte{'y'}, snap[1:]...), wErr: true},
+	}
+	for i, tt := range tests {
+		_, err := io.Copy(io.Discard, m.NewVerifier(bytes.NewReader(tt.snap)))
+		if (err != nil) != tt.wErr {
+			t.Errorf("#%d: err get=%v, want err=%v", i, err, tt.wErr)
+		}
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, util.ManifestPath("valid")), []byte(`{"sha256":"00","size":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, util.ManifestPath("malformed")), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path      string
+		wErr      bool
+		wNotExist bool
+	}{
+		{path: "valid"},
+		{path: "malformed", wErr: true},
+		{path: "missing", wErr: true, wNotExist: true},
+	}
+	for i, tt := range tests {
+		_, err := ReadManifest(reader.NewLocalReader(dir), tt.path)
+		if (err != nil) != tt.wErr || errors.Is(err, os.ErrNotExist) != tt.wNotExist {
+			t.Errorf("#%d: err get=%v, want err=%v, not exist=%v", i, err, tt.wErr, tt.wNotExist)
+		}
+	}
+}
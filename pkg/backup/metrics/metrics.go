@@ -26,10 +26,28 @@ var (
 	},
 		[]string{"name", "namespace"},
 	)
+
+	RestoreDownloadsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd_operator",
+		Name:      "restore_downloads_total",
+		Help:      "Backup downloads of restores by name, namespace and result",
+	},
+		[]string{"name", "namespace", "result"},
+	)
+
+	RestoreDownloadedBytesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd_operator",
+		Name:      "restore_downloaded_bytes_total",
+		Help:      "Bytes of backups downloaded for restores by name and namespace",
+	},
+		[]string{"name", "namespace"},
+	)
 )
 
 func init() {
 	prometheus.MustRegister(BackupsAttemptedTotal)
 	prometheus.MustRegister(BackupsSuccessTotal)
 	prometheus.MustRegister(BackupsLastSuccess)
+	prometheus.MustRegister(RestoreDownloadsTotal)
+	prometheus.MustRegister(RestoreDownloadedBytesTotal)
 }
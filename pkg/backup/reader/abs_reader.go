@@ -22,6 +22,7 @@ import (
 	"github.com/on2itsecurity/etcd-operator/pkg/backup/util"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
 )
 
 // ensure absReader satisfies reader interface.
@@ -45,6 +46,9 @@ func (absr *absReader) Open(path string) (io.ReadCloser, error) {
 	}
 
 	resp, err := absr.abs.DownloadStream(context.Background(), container, key, nil)
+	if bloberror.HasCode(err, bloberror.BlobNotFound, bloberror.ContainerNotFound) {
+		return nil, fmt.Errorf("failed to download blob: %w", notExist(err))
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to download blob: %v", err)
 	}
@@ -0,0 +1,65 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader
+
+import (
+	"io"
+
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/compression"
+)
+
+// ensure decompressingReader satisfies reader interface.
+var _ Reader = &decompressingReader{}
+
+// decompressingReader provides Reader implementation for reading compressed files with another Reader
+type decompressingReader struct {
+	r Reader
+}
+
+// NewDecompressingReader creates a reader that decompresses the backups read with r,
+// with the codec they are compressed with. Uncompressed backups are read as is.
+func NewDecompressingReader(r Reader) Reader {
+	return &decompressingReader{r}
+}
+
+// Open opens the file on path, and decompresses it while it is read
+func (dr *decompressingReader) Open(path string) (io.ReadCloser, error) {
+	rc, err := dr.r.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	d, err := compression.NewDecompressingReader(rc)
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{d, closers{d, rc}}, nil
+}
+
+// closers closes all its closers, and returns the first error.
+type closers []io.Closer
+
+func (cs closers) Close() error {
+	var err error
+	for _, c := range cs {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
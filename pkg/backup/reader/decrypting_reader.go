@@ -0,0 +1,52 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader
+
+import (
+	"io"
+
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/encryption"
+)
+
+// ensure decryptingReader satisfies reader interface.
+var _ Reader = &decryptingReader{}
+
+// decryptingReader provides Reader implementation for reading encrypted files with another Reader
+type decryptingReader struct {
+	r   Reader
+	key []byte
+}
+
+// NewDecryptingReader return a Reader implementation that decrypts the files it opens with r using key
+func NewDecryptingReader(r Reader, key []byte) Reader {
+	return &decryptingReader{r, key}
+}
+
+// Open opens the file on path, and decrypts it while it is read
+func (dr *decryptingReader) Open(path string) (io.ReadCloser, error) {
+	rc, err := dr.r.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	d, err := encryption.NewDecryptingReader(rc, dr.key)
+	if err != nil {
+		rc.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{d, rc}, nil
+}
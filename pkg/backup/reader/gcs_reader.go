@@ -16,6 +16,7 @@ package reader
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 
@@ -45,5 +46,12 @@ func (gcsr *gcsReader) Open(path string) (io.ReadCloser, error) {
 		return nil, fmt.Errorf("failed to parse gcs bucket and key: %v", err)
 	}
 
-	return gcsr.gcs.Bucket(bucket).Object(key).NewReader(gcsr.ctx)
+	rc, err := gcsr.gcs.Bucket(bucket).Object(key).NewReader(gcsr.ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) || errors.Is(err, storage.ErrBucketNotExist) {
+		return nil, notExist(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rc, nil
 }
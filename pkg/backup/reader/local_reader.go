@@ -0,0 +1,44 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reader
+
+import (
+	"io"
+	"os"
+
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/util"
+)
+
+// ensure localReader satisfies reader interface.
+var _ Reader = &localReader{}
+
+// localReader provides Reader implementation for reading a file from a mounted volume
+type localReader struct {
+	dir string
+}
+
+// NewLocalReader return a Reader implementation to read a file from the volume mounted at dir
+func NewLocalReader(dir string) Reader {
+	return &localReader{dir}
+}
+
+// Open opens the file on path, relative to the volume
+func (lr *localReader) Open(path string) (io.ReadCloser, error) {
+	name, err := util.LocalBackupPath(lr.dir, path)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(name)
+}
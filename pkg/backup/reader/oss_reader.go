@@ -15,6 +15,7 @@
 package reader
 
 import (
+	"errors"
 	"fmt"
 	"io"
 
@@ -47,7 +48,7 @@ func (ossr *ossReader) Open(path string) (io.ReadCloser, error) {
 	if err != nil {
 		return nil, err
 	} else if !exist {
-		return nil, fmt.Errorf("OSS: bucket<%s> not found", bk)
+		return nil, notExist(fmt.Errorf("OSS: bucket<%s> not found", bk))
 	}
 
 	bucket, err := ossr.client.Bucket(bk)
@@ -55,5 +56,13 @@ func (ossr *ossReader) Open(path string) (io.ReadCloser, error) {
 		return nil, err
 	}
 
-	return bucket.GetObject(key)
+	rc, err := bucket.GetObject(key)
+	var serr oss.ServiceError
+	if errors.As(err, &serr) && serr.Code == "NoSuchKey" {
+		return nil, notExist(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rc, nil
 }
@@ -14,10 +14,20 @@
 
 package reader
 
-import "io"
+import (
+	"fmt"
+	"io"
+	"os"
+)
 
 // Reader defines required reader operations
 type Reader interface {
 	// Open opens up a backup file for reading.
+	// The error wraps os.ErrNotExist if the file does not exist.
 	Open(path string) (rc io.ReadCloser, err error)
 }
+
+// notExist wraps err of a storage client with os.ErrNotExist.
+func notExist(err error) error {
+	return fmt.Errorf("%w: %w", os.ErrNotExist, err)
+}
@@ -15,12 +15,14 @@
 package reader
 
 import (
+	"errors"
 	"fmt"
 	"io"
 
 	"github.com/on2itsecurity/etcd-operator/pkg/backup/util"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/s3"
 )
 
@@ -47,6 +49,10 @@ func (s3r *s3Reader) Open(path string) (io.ReadCloser, error) {
 		Key:    aws.String(key),
 	})
 	if err != nil {
+		var aerr awserr.Error
+		if errors.As(err, &aerr) && (aerr.Code() == s3.ErrCodeNoSuchKey || aerr.Code() == s3.ErrCodeNoSuchBucket) {
+			return nil, notExist(err)
+		}
 		return nil, err
 	}
 
@@ -0,0 +1,131 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/util"
+
+	"github.com/sirupsen/logrus"
+)
+
+// EnforceRetention prunes the snapshots of basePath that neither are among the newest maxCount,
+// nor are kept by the retention policy r, evaluated at now in the time zone loc.
+// It returns the paths of the pruned snapshots, which are not deleted if r.DryRun is set.
+func (bm *BackupManager) EnforceRetention(ctx context.Context, basePath string, maxCount int, r *api.BackupRetention,
+	now time.Time, loc *time.Location) ([]string, error) {
+	paths, err := bm.bw.List(ctx, basePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get exisiting snapshots: %v", err)
+	}
+	savedSnapShots := []string{}
+	for _, p := range paths {
+		// The base path is a prefix of the paths of other backups too, e.g. bucket/etcd of bucket/etcd-prod.
+		if !util.IsManifestPath(p) && util.IsBackupPathOf(p, basePath) {
+			savedSnapShots = append(savedSnapShots, p)
+		}
+	}
+
+	expired := selectExpired(savedSnapShots, maxCount, r, now, loc)
+	if r.DryRun {
+		return expired, nil
+	}
+	for _, snapshotPath := range expired {
+		logrus.Infof("deleting snapshot %s", snapshotPath)
+		if err := bm.bw.Delete(ctx, snapshotPath); err != nil {
+			return nil, fmt.Errorf("failed to delete snapshot: %v", err)
+		}
+		// Snapshots saved by older versions have no manifest.
+		if err := bm.bw.Delete(ctx, util.ManifestPath(snapshotPath)); err != nil {
+			logrus.Warningf("failed to delete manifest of snapshot %s: %v", snapshotPath, err)
+		}
+	}
+	return expired, nil
+}
+
+// selectExpired returns the snapshots of paths that are to be pruned, newest first.
+// Snapshots without a timestamp in their path are never pruned.
+func selectExpired(paths []string, maxCount int, r *api.BackupRetention, now time.Time, loc *time.Location) []string {
+	sorted := append([]string(nil), paths...)
+	sort.Sort(sort.Reverse(util.SortableBackupPaths(sort.StringSlice(sorted))))
+
+	type snapshot struct {
+		path string
+		t    time.Time
+	}
+	var snapshots []snapshot
+	for _, p := range sorted {
+		if t, ok := util.BackupTimestamp(p); ok {
+			snapshots = append(snapshots, snapshot{p, t.In(loc)})
+		}
+	}
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	periods := []struct {
+		count int
+		key   func(t time.Time) string
+	}{
+		{r.Hourly, func(t time.Time) string { return t.Format("2006-01-02-15") }},
+		{r.Daily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{r.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-%d", year, week)
+		}},
+		{r.Monthly, func(t time.Time) string { return t.Format("2006-01") }},
+		{r.Yearly, func(t time.Time) string { return t.Format("2006") }},
+	}
+
+	// Without counts, only the maximal age applies.
+	countBased := maxCount > 0
+	keep := map[string]bool{snapshots[0].path: true}
+	for i := 0; i < maxCount && i < len(snapshots); i++ {
+		keep[snapshots[i].path] = true
+	}
+	for _, period := range periods {
+		if period.count <= 0 {
+			continue
+		}
+		countBased = true
+		seen := map[string]bool{}
+		for _, s := range snapshots {
+			k := period.key(s.t)
+			if seen[k] {
+				continue
+			}
+			if len(seen) == period.count {
+				break
+			}
+			seen[k] = true
+			keep[s.path] = true
+		}
+	}
+
+	maxAge := time.Duration(r.MaxAgeInSecond) * time.Second
+	var expired []string
+	for i, s := range snapshots {
+		tooOld := maxAge > 0 && now.Sub(s.t) > maxAge
+		if i != 0 && (tooOld || (countBased && !keep[s.path])) {
+			expired = append(expired, s.path)
+		}
+	}
+	return expired
+}
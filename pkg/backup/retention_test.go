@@ -0,0 +1,158 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/util"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/writer"
+)
+
+func TestSelectExpired(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	// a backup every 6 hours for 90 days, newest first
+	var paths []string
+	for i := 0; i < 90*4; i++ {
+		paths = append(paths, util.PeriodicBackupPath("etcd.backup", int64(1000-i), now.Add(-time.Duration(i)*6*time.Hour)))
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		maxCount int
+		r        api.BackupRetention
+		loc      *time.Location
+		wantKept []string
+	}{{
+		// the newest backups
+		maxCount: 2,
+		loc:      time.UTC,
+		wantKept: paths[:2],
+	}, {
+		// the newest backup of each of the last 3 days
+		r:        api.BackupRetention{Daily: 3},
+		loc:      time.UTC,
+		wantKept: []string{paths[0], paths[3], paths[7]},
+	}, {
+		// days in the time zone end at 04:00 UTC
+		r:        api.BackupRetention{Daily: 3},
+		loc:      newYork,
+		wantKept: []string{paths[0], paths[2], paths[6]},
+	}, {
+		// buckets overlap, March 31 2026 is a Tuesday
+		r:        api.BackupRetention{Hourly: 2, Daily: 2, Weekly: 2, Monthly: 3},
+		loc:      time.UTC,
+		wantKept: []string{paths[0], paths[1], paths[3], paths[7], paths[123], paths[235]},
+	}, {
+		// only the maximal age
+		r:        api.BackupRetention{MaxAgeInSecond: 24 * 3600},
+		loc:      time.UTC,
+		wantKept: paths[:5],
+	}, {
+		// the maximal age overrules the counts
+		r:        api.BackupRetention{Daily: 10, MaxAgeInSecond: 2 * 24 * 3600},
+		loc:      time.UTC,
+		wantKept: []string{paths[0], paths[3], paths[7]},
+	}, {
+		// the newest backup is always kept
+		r:        api.BackupRetention{MaxAgeInSecond: 1},
+		loc:      time.UTC,
+		wantKept: paths[:1],
+	}}
+	for i, tt := range tests {
+		expired := selectExpired(append([]string{"etcd.backup"}, paths...), tt.maxCount, &tt.r, now, tt.loc)
+		isExpired := map[string]bool{}
+		for _, p := range expired {
+			isExpired[p] = true
+		}
+		if isExpired["etcd.backup"] {
+			t.Errorf("#%d: expected a backup without timestamp to be kept", i)
+		}
+		var kept []string
+		for _, p := range paths {
+			if !isExpired[p] {
+				kept = append(kept, p)
+			}
+		}
+		if !reflect.DeepEqual(kept, tt.wantKept) {
+			t.Errorf("#%d: kept get=%v, want=%v", i, kept, tt.wantKept)
+		}
+	}
+}
+
+func TestEnforceRetention(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	for i, dryRun := range []bool{true, false} {
+		w := writer.NewLocalWriter(t.TempDir())
+		bm := NewBackupManagerFromWriter(nil, w, nil, nil, "", "", "", api.BackupCompressionNone)
+
+		var paths []string
+		for j := 0; j < 4; j++ {
+			p := util.PeriodicBackupPath("etcd.backup", int64(j), now.Add(-time.Duration(j)*24*time.Hour))
+			paths = append(paths, p)
+			for _, name := range []string{p, util.ManifestPath(p)} {
+				if _, err := w.Write(ctx, name, strings.NewReader(name)); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		// The backups of another EtcdBackup, whose base path starts with the same prefix, are left alone.
+		var siblings []string
+		for j := 0; j < 4; j++ {
+			p := util.PeriodicBackupPath("etcd.backup-prod", int64(j), now.Add(-time.Duration(j+10)*24*time.Hour))
+			siblings = append(siblings, p)
+			if _, err := w.Write(ctx, p, strings.NewReader(p)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		r := &api.BackupRetention{Daily: 2, DryRun: dryRun}
+		pruned, err := bm.EnforceRetention(ctx, "etcd.backup", 0, r, now, time.UTC)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := paths[2:]; !reflect.DeepEqual(pruned, want) {
+			t.Errorf("#%d: pruned get=%v, want=%v", i, pruned, want)
+		}
+
+		got, err := w.List(ctx, "etcd.backup")
+		if err != nil {
+			t.Fatal(err)
+		}
+		remaining := paths
+		if !dryRun {
+			remaining = paths[:2]
+		}
+		want := append([]string(nil), siblings...)
+		for _, p := range remaining {
+			want = append(want, p, util.ManifestPath(p))
+		}
+		sort.Strings(got)
+		sort.Strings(want)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("#%d: remaining get=%v, want=%v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package seed saves the backup the seed member of a restored cluster restores from,
+// reading it from the backup storage with the secrets mounted into the seed member.
+package seed
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/encryption"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/reader"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Config tells where to read the backup, and where to save it.
+type Config struct {
+	// Spec is the spec of the restore, with the exact path of the backup in its RestoreSource.
+	Spec api.RestoreSpec
+	// SourceSecretDir is the directory the secret of the RestoreSource is mounted at.
+	SourceSecretDir string
+	// EncryptionSecretDir is the directory the secret of the Encryption is mounted at.
+	EncryptionSecretDir string
+	// Output is the file the backup is saved to.
+	Output string
+}
+
+// Fetch saves the backup of the restore described by cfg to cfg.Output,
+// after it is decrypted, decompressed and verified against its manifest.
+func Fetch(ctx context.Context, cfg Config) error {
+	backupReader, path, closeReader, err := newBackupReader(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer closeReader()
+	return saveBackup(backupReader, path, cfg.Spec.Encryption != nil, cfg.Output)
+}
+
+// saveBackup saves the backup at path to output. The backup is removed if it can not be verified.
+func saveBackup(r reader.Reader, path string, decrypt bool, output string) (err error) {
+	rc, err := r.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to read backup file(%s): %v", path, err)
+	}
+	defer rc.Close()
+
+	var src io.Reader = rc
+	m, err := backup.ReadManifest(r, path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read manifest of backup file(%s): %v", path, err)
+	}
+	if err != nil {
+		// Without a manifest, at least check that the backup can be decrypted, or is not encrypted.
+		start := make([]byte, len(encryption.Magic))
+		n, rerr := io.ReadFull(rc, start)
+		if rerr != nil && rerr != io.ErrUnexpectedEOF && rerr != io.EOF {
+			return fmt.Errorf("failed to read backup file(%s): %v", path, rerr)
+		}
+		if !decrypt && encryption.IsEncrypted(start[:n]) {
+			return fmt.Errorf("backup file(%s) is encrypted, spec.encryption must be set", path)
+		}
+		logrus.Warningf("not verifying backup file(%s), it has no manifest", path)
+		src = io.MultiReader(bytes.NewReader(start[:n]), rc)
+	} else {
+		src = m.NewVerifier(rc)
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("failed to create backup file(%s): %v", output, err)
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("failed to save backup file(%s): %v", output, cerr)
+		}
+		if err != nil {
+			os.Remove(output)
+		}
+	}()
+	n, err := io.Copy(f, src)
+	if err != nil {
+		return fmt.Errorf("failed to save backup file(%s): %v", path, err)
+	}
+	logrus.Infof("saved backup file(%s) of %d bytes to %s", path, n, output)
+	return nil
+}
+
+// newBackupReader returns the reader of the backup storage of cfg, and the path of the backup in it.
+// The reader decrypts and decompresses the backups it opens. closeReader releases the clients of the reader.
+func newBackupReader(ctx context.Context, cfg Config) (backupReader reader.Reader, path string, closeReader func(), err error) {
+	spec := cfg.Spec
+	var sourceData, encryptionData map[string][]byte
+	if len(spec.RestoreSource.Secret(spec.BackupStorageType)) != 0 {
+		sourceData, err = readSecretDir(cfg.SourceSecretDir)
+		if err != nil {
+			return nil, "", nil, err
+		}
+	}
+	if spec.Encryption != nil {
+		encryptionData, err = readSecretDir(cfg.EncryptionSecretDir)
+		if err != nil {
+			return nil, "", nil, err
+		}
+	}
+	// The seed member has no Local backup storage, the restore operator rejects Direct restores from it.
+	backupReader, _, path, closeReader, err = backup.NewReader(ctx, spec, sourceData, encryptionData, "")
+	return backupReader, path, closeReader, err
+}
+
+// readSecretDir returns the data of the secret mounted at dir.
+func readSecretDir(dir string) (map[string][]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret directory (%s): %v", dir, err)
+	}
+	data := map[string][]byte{}
+	for _, e := range entries {
+		// The keys of a mounted secret link to the files in the hidden directory of its current version.
+		if strings.HasPrefix(e.Name(), "..") || e.IsDir() {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secret key (%s): %v", e.Name(), err)
+		}
+		data[e.Name()] = b
+	}
+	return data, nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package seed
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/on2itsecurity/etcd-operator/pkg/backup"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/encryption"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/reader"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/util"
+)
+
+func writeBackup(t *testing.T, dir, path string, b []byte, m *backup.Manifest) {
+	if err := os.WriteFile(filepath.Join(dir, path), b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if m == nil {
+		return
+	}
+	mb, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, util.ManifestPath(path)), mb, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSaveBackup(t *testing.T) {
+	dir := t.TempDir()
+	snap := bytes.Repeat([]byte("etcd"), 128)
+	h := sha256.Sum256(snap)
+	m := &backup.Manifest{SHA256: hex.EncodeToString(h[:]), Size: int64(len(snap))}
+	writeBackup(t, dir, "verified", snap, m)
+	writeBackup(t, dir, "corrupt", snap[1:], m)
+	writeBackup(t, dir, "unverified", snap, nil)
+	writeBackup(t, dir, "encrypted", append([]byte(encryption.Magic), snap...), nil)
+	// A manifest that can not be read must not be taken for a missing manifest.
+	writeBackup(t, dir, "malformed", snap, nil)
+	if err := os.WriteFile(filepath.Join(dir, util.ManifestPath("malformed")), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		decrypt bool
+		wErr    bool
+	}{
+		{path: "verified"},
+		{path: "corrupt", wErr: true},
+		{path: "unverified"},
+		{path: "encrypted", wErr: true},
+		{path: "malformed", wErr: true},
+		{path: "missing", wErr: true},
+	}
+	for i, tt := range tests {
+		output := filepath.Join(t.TempDir(), "backup")
+		err := saveBackup(reader.NewLocalReader(dir), tt.path, tt.decrypt, output)
+		if (err != nil) != tt.wErr {
+			t.Errorf("#%d: error=%v, want error=%v", i, err, tt.wErr)
+			continue
+		}
+		b, rerr := os.ReadFile(output)
+		if tt.wErr {
+			if !os.IsNotExist(rerr) {
+				t.Errorf("#%d: backup is not removed after error", i)
+			}
+			continue
+		}
+		if !bytes.Equal(b, snap) {
+			t.Errorf("#%d: saved backup does not match the backup", i)
+		}
+	}
+}
+
+func TestReadSecretDir(t *testing.T) {
+	// A mounted secret links its keys to the hidden directory of its current version.
+	dir := t.TempDir()
+	version := filepath.Join(dir, "..2026_01_02_03_04_05.000000001")
+	if err := os.Mkdir(version, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(version, "credentials"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Base(version), filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..data", "credentials"), filepath.Join(dir, "credentials")); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := readSecretDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 1 || string(data["credentials"]) != "secret" {
+		t.Errorf("secret data get=%v, want only credentials", data)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/encryption"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/reader"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/writer"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/alibabacloudutil/ossfactory"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/awsutil/s3factory"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/azureutil/absfactory"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/constants"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/gcputil/gcsfactory"
+
+	"k8s.io/client-go/kubernetes"
+)
+
+// NewWriter returns a writer to the storage of storageType in source, with the secrets in namespace,
+// the path of source in that storage, and a function to release the writer.
+// The writer encrypts the backups with the key of enc, if it is set.
+// Local storage is the volume mounted at constants.BackupMountDir.
+func NewWriter(ctx context.Context, kubecli kubernetes.Interface, namespace string, storageType api.BackupStorageType,
+	source *api.BackupSource, enc *api.BackupEncryption) (bw writer.Writer, path string, closeWriter func(), err error) {
+	closeWriter = func() {}
+	switch storageType {
+	case api.BackupStorageTypeS3:
+		s := source.S3
+		if s == nil {
+			return nil, "", nil, errors.New("empty s3 backup source")
+		}
+		cli, err := s3factory.NewClientFromSecret(ctx, kubecli, namespace, s.Endpoint, s.AWSSecret, s.ForcePathStyle)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to create S3 client: %v", err)
+		}
+		bw, path, closeWriter = writer.NewS3Writer(cli.S3), s.Path, cli.Close
+	case api.BackupStorageTypeABS:
+		s := source.ABS
+		if s == nil {
+			return nil, "", nil, errors.New("empty abs backup source")
+		}
+		cli, err := absfactory.NewClientFromSecret(ctx, kubecli, namespace, s.ABSSecret)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to create ABS client: %v", err)
+		}
+		bw, path = writer.NewABSWriter(cli.ServiceClient), s.Path
+	case api.BackupStorageTypeGCS:
+		s := source.GCS
+		if s == nil {
+			return nil, "", nil, errors.New("empty gcs backup source")
+		}
+		cli, err := gcsfactory.NewClientFromSecret(ctx, kubecli, namespace, s.GCPSecret)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to create GCS client: %v", err)
+		}
+		bw, path, closeWriter = writer.NewGCSWriter(cli.GCS), s.Path, func() { cli.GCS.Close() }
+	case api.BackupStorageTypeOSS:
+		s := source.OSS
+		if s == nil {
+			return nil, "", nil, errors.New("empty oss backup source")
+		}
+		cli, err := ossfactory.NewClientFromSecret(ctx, kubecli, namespace, s.Endpoint, s.OSSSecret)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to create OSS client: %v", err)
+		}
+		bw, path = writer.NewOSSWriter(cli.OSS), s.Path
+	case api.BackupStorageTypeLocal:
+		s := source.Local
+		if s == nil {
+			return nil, "", nil, errors.New("empty local backup source")
+		}
+		bw, path = writer.NewLocalWriter(constants.BackupMountDir), s.Path
+	default:
+		return nil, "", nil, fmt.Errorf("unsupported backup storage type (%s)", storageType)
+	}
+
+	if enc != nil {
+		key, err := encryption.KeyFromSecret(ctx, kubecli, namespace, enc)
+		if err != nil {
+			closeWriter()
+			return nil, "", nil, err
+		}
+		bw = writer.NewEncryptedWriter(bw, key)
+	}
+	return bw, path, closeWriter, nil
+}
+
+// NewReader returns a reader of the backup storage of spec, a lister of the backups in that storage,
+// the path of the RestoreSource of spec in it, and a function to release the reader and lister.
+// sourceData is the data of the secret of the RestoreSource, and encryptionData the data of the secret of
+// spec.Encryption, if it is set. The reader decrypts and decompresses the backups it opens.
+// Local storage is the directory localDir.
+func NewReader(ctx context.Context, spec api.RestoreSpec, sourceData, encryptionData map[string][]byte,
+	localDir string) (br reader.Reader, lister writer.Writer, path string, closeReader func(), err error) {
+	closeReader = func() {}
+	switch spec.BackupStorageType {
+	case api.BackupStorageTypeS3:
+		s := spec.S3
+		if s == nil {
+			return nil, nil, "", nil, errors.New("empty s3 restore source")
+		}
+		if len(s.AWSSecret) == 0 || len(s.Path) == 0 {
+			return nil, nil, "", nil, errors.New("invalid s3 restore source field (spec.s3), must specify all required subfields")
+		}
+		cli, err := s3factory.NewClientFromSecretData(sourceData, s.Endpoint, s.ForcePathStyle)
+		if err != nil {
+			return nil, nil, "", nil, fmt.Errorf("failed to create S3 client: %v", err)
+		}
+		br, lister, path, closeReader = reader.NewS3Reader(cli.S3), writer.NewS3Writer(cli.S3), s.Path, cli.Close
+	case api.BackupStorageTypeABS:
+		s := spec.ABS
+		if s == nil {
+			return nil, nil, "", nil, errors.New("empty abs restore source")
+		}
+		if len(s.ABSSecret) == 0 || len(s.Path) == 0 {
+			return nil, nil, "", nil, errors.New("invalid abs restore source field (spec.abs), must specify all required subfields")
+		}
+		cli, err := absfactory.NewClientFromSecretData(sourceData)
+		if err != nil {
+			return nil, nil, "", nil, fmt.Errorf("failed to create ABS client: %v", err)
+		}
+		br, lister, path = reader.NewABSReader(cli.BlobClient), writer.NewABSWriter(cli.ServiceClient), s.Path
+	case api.BackupStorageTypeGCS:
+		s := spec.GCS
+		if s == nil {
+			return nil, nil, "", nil, errors.New("empty gcs restore source")
+		}
+		if len(s.Path) == 0 {
+			return nil, nil, "", nil, errors.New("invalid gcs restore source field (spec.gcs), must specify all required subfields")
+		}
+		cli, err := gcsfactory.NewClientFromSecretData(ctx, sourceData)
+		if err != nil {
+			return nil, nil, "", nil, fmt.Errorf("failed to create GCS client: %v", err)
+		}
+		br, lister, path, closeReader = reader.NewGCSReader(ctx, cli.GCS), writer.NewGCSWriter(cli.GCS), s.Path, func() { cli.GCS.Close() }
+	case api.BackupStorageTypeOSS:
+		s := spec.OSS
+		if s == nil {
+			return nil, nil, "", nil, errors.New("empty oss restore source")
+		}
+		if len(s.OSSSecret) == 0 || len(s.Path) == 0 {
+			return nil, nil, "", nil, errors.New("invalid oss restore source field (spec.oss), must specify all required subfields")
+		}
+		cli, err := ossfactory.NewClientFromSecretData(sourceData, s.Endpoint)
+		if err != nil {
+			return nil, nil, "", nil, fmt.Errorf("failed to create OSS client: %v", err)
+		}
+		br, lister, path = reader.NewOSSReader(cli.OSS), writer.NewOSSWriter(cli.OSS), s.Path
+	case api.BackupStorageTypeLocal:
+		s := spec.Local
+		if s == nil {
+			return nil, nil, "", nil, errors.New("empty local restore source")
+		}
+		if len(s.Path) == 0 {
+			return nil, nil, "", nil, errors.New("invalid local restore source field (spec.local), must specify all required subfields")
+		}
+		br, lister, path = reader.NewLocalReader(localDir), writer.NewLocalWriter(localDir), s.Path
+	default:
+		return nil, nil, "", nil, fmt.Errorf("unsupported backup storage type (%s)", spec.BackupStorageType)
+	}
+
+	if spec.Encryption != nil {
+		key, err := encryption.KeyFromSecretData(encryptionData, spec.Encryption)
+		if err != nil {
+			closeReader()
+			return nil, nil, "", nil, err
+		}
+		br = reader.NewDecryptingReader(br, key)
+	}
+	// Compressed backups are detected from their first bytes.
+	return reader.NewDecompressingReader(br), lister, path, closeReader, nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/encryption"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNewWriter(t *testing.T) {
+	key := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "key", Namespace: "default"},
+		Data:       map[string][]byte{"encryption-key": bytes.Repeat([]byte{'k'}, encryption.KeySize)},
+	}
+	kubecli := fake.NewSimpleClientset(key)
+	local := &api.BackupSource{Local: &api.LocalBackupSource{Path: "mycluster/etcd.backup"}}
+
+	tests := []struct {
+		storageType api.BackupStorageType
+		source      *api.BackupSource
+		enc         *api.BackupEncryption
+		wPath       string
+		wErr        bool
+	}{
+		{storageType: api.BackupStorageTypeLocal, source: local, wPath: "mycluster/etcd.backup"},
+		{storageType: api.BackupStorageTypeLocal, source: local, enc: &api.BackupEncryption{Secret: "key"}, wPath: "mycluster/etcd.backup"},
+		{storageType: api.BackupStorageTypeLocal, source: local, enc: &api.BackupEncryption{Secret: "missing"}, wErr: true},
+		{storageType: api.BackupStorageTypeS3, source: local, wErr: true},
+		{storageType: api.BackupStorageTypeS3, source: &api.BackupSource{S3: &api.S3BackupSource{Path: "bucket/etcd.backup", AWSSecret: "missing"}}, wErr: true},
+		{storageType: "unknown", source: local, wErr: true},
+	}
+	for i, tt := range tests {
+		bw, path, closeWriter, err := NewWriter(context.Background(), kubecli, "default", tt.storageType, tt.source, tt.enc)
+		if (err != nil) != tt.wErr {
+			t.Errorf("#%d: err get=%v, want err=%v", i, err, tt.wErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		closeWriter()
+		if bw == nil || path != tt.wPath {
+			t.Errorf("#%d: path get=%q, want=%q", i, path, tt.wPath)
+		}
+	}
+}
+
+func TestNewReader(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "etcd.backup"), []byte("snapshot"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	local := api.RestoreSource{Local: &api.LocalRestoreSource{Path: "etcd.backup"}}
+	keyData := map[string][]byte{"encryption-key": bytes.Repeat([]byte{'k'}, encryption.KeySize)}
+
+	tests := []struct {
+		spec           api.RestoreSpec
+		encryptionData map[string][]byte
+		wErr           bool
+	}{
+		{spec: api.RestoreSpec{BackupStorageType: api.BackupStorageTypeLocal, RestoreSource: local}},
+		{spec: api.RestoreSpec{BackupStorageType: api.BackupStorageTypeLocal, RestoreSource: local, Encryption: &api.BackupEncryption{Secret: "key"}}, encryptionData: keyData},
+		{spec: api.RestoreSpec{BackupStorageType: api.BackupStorageTypeLocal, RestoreSource: local, Encryption: &api.BackupEncryption{Secret: "key"}}, wErr: true},
+		{spec: api.RestoreSpec{BackupStorageType: api.BackupStorageTypeLocal, RestoreSource: api.RestoreSource{Local: &api.LocalRestoreSource{}}}, wErr: true},
+		{spec: api.RestoreSpec{BackupStorageType: api.BackupStorageTypeS3, RestoreSource: local}, wErr: true},
+		{spec: api.RestoreSpec{BackupStorageType: api.BackupStorageTypeS3, RestoreSource: api.RestoreSource{S3: &api.S3RestoreSource{Path: "bucket/etcd.backup"}}}, wErr: true},
+		{spec: api.RestoreSpec{BackupStorageType: "unknown", RestoreSource: local}, wErr: true},
+	}
+	for i, tt := range tests {
+		br, lister, path, closeReader, err := NewReader(context.Background(), tt.spec, nil, tt.encryptionData, dir)
+		if (err != nil) != tt.wErr {
+			t.Errorf("#%d: err get=%v, want err=%v", i, err, tt.wErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		closeReader()
+		if path != "etcd.backup" {
+			t.Errorf("#%d: path get=%q, want=etcd.backup", i, path)
+		}
+		paths, err := lister.List(context.Background(), "etcd")
+		if err != nil || len(paths) != 1 {
+			t.Errorf("#%d: list get=%v, %v, want the backup", i, paths, err)
+		}
+		if tt.spec.Encryption != nil {
+			// The plain backup is rejected by the decrypting reader.
+			continue
+		}
+		rc, err := br.Open(path)
+		if err != nil {
+			t.Errorf("#%d: open failed: %v", i, err)
+			continue
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil || string(b) != "snapshot" {
+			t.Errorf("#%d: read get=%q, %v, want=snapshot", i, b, err)
+		}
+	}
+}
@@ -16,4 +16,6 @@ package util
 
 const (
 	BackupFilenameSuffix = "etcd.backup"
+	// ManifestSuffix is appended to the path of a snapshot to get the path of its manifest.
+	ManifestSuffix = ".manifest.json"
 )
@@ -16,10 +16,13 @@ package util
 
 import (
 	"fmt"
+	"path"
+	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func MakeBackupName(ver string, rev int64) string {
@@ -36,15 +39,92 @@ func ParseBucketAndKey(path string) (string, string, error) {
 	return toks[0], toks[1], nil
 }
 
+// LocalBackupPath returns the path of a backup file on the volume mounted at dir.
+// returns error if path is not a relative path within the volume.
+func LocalBackupPath(dir, path string) (string, error) {
+	if !filepath.IsLocal(path) {
+		return "", fmt.Errorf("invalid local path (%v)", path)
+	}
+	return filepath.Join(dir, path), nil
+}
+
+// ManifestPath returns the path of the manifest of the snapshot at path.
+func ManifestPath(path string) string {
+	return path + ManifestSuffix
+}
+
+// IsManifestPath tells whether path is the path of a manifest, rather than of a snapshot.
+func IsManifestPath(path string) bool {
+	return strings.HasSuffix(path, ManifestSuffix)
+}
+
+// PeriodicBackupPath returns the path of a periodic backup taken at revision rev and time t.
+// NOTE: make sure this path format stays in sync with SortableBackupPaths
+func PeriodicBackupPath(basePath string, rev int64, t time.Time) string {
+	return fmt.Sprintf("%s_v%d_%s", basePath, rev, t.UTC().Format(backupTimestampLayout))
+}
+
+// IsBackupPathOf tells whether path is the path of a backup saved at basePath: basePath itself,
+// or the path of a periodic backup of basePath, with the extension of its compression codec if it is compressed.
+func IsBackupPathOf(path, basePath string) bool {
+	if path == basePath {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(path, basePath)
+	return ok && periodicBackupSuffixRegex.MatchString(suffix)
+}
+
+// UpgradeBackupPath returns the path of the backup taken at time t, before the cluster backed up at basePath
+// is upgraded to version. It is in the directory "pre-upgrade" next to basePath, so that it is not pruned
+// with the periodic backups at basePath.
+func UpgradeBackupPath(basePath, version string, t time.Time) string {
+	dir, file := path.Split(basePath)
+	return fmt.Sprintf("%spre-upgrade/%s_to-%s_%s", dir, file, version, t.UTC().Format(backupTimestampLayout))
+}
+
 // SortableBackupPaths implements extends sort.StringSlice to allow sorting to work
 // with paths used for backups, in the format "<base path>_v<etcd store revision>_YYYY-MM-DD-HH:mm:SS",
 // where the timestamp is what is being sorted on.
 type SortableBackupPaths sort.StringSlice
 
+// backupTimestampLayout is the layout of the UTC timestamp in the paths of periodic backups.
+const backupTimestampLayout = "2006-01-02-15:04:05"
+
 // regular expressions used in backup path order comparison
 var backupTimestampRegex = regexp.MustCompile(`_\d+-\d+-\d+-\d+:\d+:\d+`)
 var etcdStoreRevisionRegex = regexp.MustCompile(`_v\d+`)
 
+// periodicBackupSuffixRegex matches what PeriodicBackupPath appends to the base path, and the extension of a compression codec.
+var periodicBackupSuffixRegex = regexp.MustCompile(`^_v\d+_\d{4}-\d{2}-\d{2}-\d{2}:\d{2}:\d{2}(\.gz|\.zst)?$`)
+
+// BackupTimestamp returns the time a backup was taken, from the last timestamp in its path.
+// It returns false if path has no timestamp, see PeriodicBackupPath.
+func BackupTimestamp(path string) (time.Time, bool) {
+	matches := backupTimestampRegex.FindAllString(path, -1)
+	if len(matches) == 0 {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(backupTimestampLayout, matches[len(matches)-1][1:])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// BackupRevision returns the etcd store revision a backup was taken at, from the last revision in its path.
+// It returns false if path has no revision, see PeriodicBackupPath.
+func BackupRevision(path string) (int64, bool) {
+	matches := etcdStoreRevisionRegex.FindAllString(path, -1)
+	if len(matches) == 0 {
+		return 0, false
+	}
+	rev, err := strconv.ParseInt(matches[len(matches)-1][2:], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return rev, true
+}
+
 // Len is the number of elements in the collection.
 func (s SortableBackupPaths) Len() int {
 	return len(s)
@@ -17,7 +17,9 @@ package util
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 // Tests that SortableBackupPaths.Len() simply delegates the call to the slice.
@@ -224,6 +226,24 @@ func TestSortableBackupPathsLess(t *testing.T) {
 			lesserIndex:  0,
 			greaterIndex: 1,
 		},
+		// compressed backups, with the extension of the codec after the timestamp
+		{
+			backupPaths: []string{
+				"prefix_v20_2020-01-07-11:00:00.zst",
+				"prefix_v10_2020-01-07-10:00:00.gz",
+				"prefix_v30_2020-01-07-12:00:00",
+			},
+			lesserIndex:  1,
+			greaterIndex: 0,
+		},
+		{
+			backupPaths: []string{
+				"prefix_v10_2020-01-07-10:00:00",
+				"prefix_v20_2020-01-07-11:00:00.gz",
+			},
+			lesserIndex:  0,
+			greaterIndex: 1,
+		},
 		// a path with a completely wrong format is considered older
 		{
 			backupPaths: []string{
@@ -345,3 +365,113 @@ func TestSortableBackupPathsSwap(t *testing.T) {
 		})
 	}
 }
+
+func TestLocalBackupPath(t *testing.T) {
+	tests := []struct {
+		path  string
+		wPath string
+		wErr  bool
+	}{
+		{path: "etcd.backup", wPath: "/backup/etcd.backup"},
+		{path: "mycluster/etcd.backup", wPath: "/backup/mycluster/etcd.backup"},
+		{path: "mycluster/../etcd.backup", wPath: "/backup/etcd.backup"},
+		{path: "", wErr: true},
+		{path: "/etc/passwd", wErr: true},
+		{path: "../etcd.backup", wErr: true},
+		{path: "mycluster/../../etcd.backup", wErr: true},
+	}
+	for i, tt := range tests {
+		path, err := LocalBackupPath("/backup", tt.path)
+		if (err != nil) != tt.wErr {
+			t.Errorf("#%d: err get=%v, want err=%v", i, err, tt.wErr)
+			continue
+		}
+		if path != tt.wPath {
+			t.Errorf("#%d: path get=%s, want=%s", i, path, tt.wPath)
+		}
+	}
+}
+
+func TestBackupTimestamp(t *testing.T) {
+	ts := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	tests := []struct {
+		path string
+		wTs  time.Time
+		wOk  bool
+	}{
+		{path: PeriodicBackupPath("bucket/etcd.backup", 42, ts), wTs: ts, wOk: true},
+		{path: PeriodicBackupPath("bucket/etcd.backup", 42, ts) + ".zst", wTs: ts, wOk: true},
+		{path: PeriodicBackupPath("bucket/etcd.backup_v1_2020-01-01-10:00:00", 42, ts), wTs: ts, wOk: true},
+		{path: "bucket/etcd.backup"},
+		{path: "bucket/etcd.backup_v42_2026-13-04-05:06:07"},
+	}
+	for i, tt := range tests {
+		got, ok := BackupTimestamp(tt.path)
+		if ok != tt.wOk || !got.Equal(tt.wTs) {
+			t.Errorf("#%d: get=%v, %v, want=%v, %v", i, got, ok, tt.wTs, tt.wOk)
+		}
+	}
+}
+
+func TestBackupRevision(t *testing.T) {
+	ts := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	tests := []struct {
+		path string
+		wRev int64
+		wOk  bool
+	}{
+		{path: PeriodicBackupPath("bucket/etcd.backup", 42, ts), wRev: 42, wOk: true},
+		{path: PeriodicBackupPath("bucket/etcd.backup_v1_2020-01-01-10:00:00", 42, ts), wRev: 42, wOk: true},
+		{path: "bucket/etcd.backup"},
+		{path: "bucket/etcd.backup_v99999999999999999999_2026-03-04-05:06:07"},
+	}
+	for i, tt := range tests {
+		got, ok := BackupRevision(tt.path)
+		if ok != tt.wOk || got != tt.wRev {
+			t.Errorf("#%d: get=%v, %v, want=%v, %v", i, got, ok, tt.wRev, tt.wOk)
+		}
+	}
+}
+
+func TestIsBackupPathOf(t *testing.T) {
+	ts := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "bucket/etcd", want: true},
+		{path: PeriodicBackupPath("bucket/etcd", 42, ts), want: true},
+		{path: PeriodicBackupPath("bucket/etcd", 42, ts) + ".gz", want: true},
+		{path: PeriodicBackupPath("bucket/etcd", 42, ts) + ".zst", want: true},
+		{path: PeriodicBackupPath("bucket/etcd-staging", 42, ts)},
+		{path: PeriodicBackupPath("bucket/etcd_v1", 42, ts)},
+		{path: "bucket/etcd_v42"},
+		{path: "bucket/etcd.backup"},
+		{path: UpgradeBackupPath("bucket/etcd", "v3.6.10", ts)},
+	}
+	for i, tt := range tests {
+		if got := IsBackupPathOf(tt.path, "bucket/etcd"); got != tt.want {
+			t.Errorf("#%d: %s get=%v, want=%v", i, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestUpgradeBackupPath(t *testing.T) {
+	ts := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	tests := []struct {
+		basePath string
+		want     string
+	}{
+		{"bucket/etcd.backup", "bucket/pre-upgrade/etcd.backup_to-v3.6.10_2026-03-04-05:06:07"},
+		{"etcd.backup", "pre-upgrade/etcd.backup_to-v3.6.10_2026-03-04-05:06:07"},
+	}
+	for i, tt := range tests {
+		got := UpgradeBackupPath(tt.basePath, "v3.6.10", ts)
+		if got != tt.want {
+			t.Errorf("#%d: get=%s, want=%s", i, got, tt.want)
+		}
+		if strings.HasPrefix(got, tt.basePath) {
+			t.Errorf("#%d: %s is listed with the backups at %s", i, got, tt.basePath)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"context"
+	"io"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/compression"
+)
+
+var _ Writer = &compressedWriter{}
+
+type compressedWriter struct {
+	Writer
+	compression api.BackupCompression
+}
+
+// NewCompressedWriter creates a writer that compresses backup files with codec c before writing them with w.
+func NewCompressedWriter(w Writer, c api.BackupCompression) Writer {
+	return &compressedWriter{w, c}
+}
+
+// Write compresses the backup file and writes it to the given path, and returns the size of the compressed file.
+func (cw *compressedWriter) Write(ctx context.Context, path string, r io.Reader) (int64, error) {
+	cr, err := compression.NewCompressingReader(r, cw.compression)
+	if err != nil {
+		return 0, err
+	}
+	defer cr.Close()
+	return cw.Writer.Write(ctx, path, cr)
+}
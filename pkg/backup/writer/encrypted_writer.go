@@ -0,0 +1,43 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"context"
+	"io"
+
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/encryption"
+)
+
+var _ Writer = &encryptedWriter{}
+
+type encryptedWriter struct {
+	Writer
+	key []byte
+}
+
+// NewEncryptedWriter creates a writer that encrypts backup files with key before writing them with w.
+func NewEncryptedWriter(w Writer, key []byte) Writer {
+	return &encryptedWriter{w, key}
+}
+
+// Write encrypts the backup file and writes it to the given path, and returns the size of the encrypted file.
+func (ew *encryptedWriter) Write(ctx context.Context, path string, r io.Reader) (int64, error) {
+	er, err := encryption.NewEncryptingReader(r, ew.key)
+	if err != nil {
+		return 0, err
+	}
+	return ew.Writer.Write(ctx, path, er)
+}
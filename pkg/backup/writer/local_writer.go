@@ -0,0 +1,103 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/util"
+)
+
+var _ Writer = &localWriter{}
+
+type localWriter struct {
+	dir string
+}
+
+// NewLocalWriter creates a writer that saves backups on the volume mounted at dir.
+func NewLocalWriter(dir string) Writer {
+	return &localWriter{dir}
+}
+
+// Write writes the backup file to the given path, relative to the volume.
+// The file is written next to its final path first, so that a failed write does not leave a partial backup.
+func (lw *localWriter) Write(ctx context.Context, p string, r io.Reader) (int64, error) {
+	name, err := util.LocalBackupPath(lw.dir, p)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o700); err != nil {
+		return 0, fmt.Errorf("failed to create backup directory: %v", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create backup file: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	n, err := io.Copy(f, r)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return n, fmt.Errorf("failed to write backup file: %v", err)
+	}
+	if err := os.Rename(f.Name(), name); err != nil {
+		return n, fmt.Errorf("failed to write backup file: %v", err)
+	}
+	return n, nil
+}
+
+// List returns the backup files whose path starts with basePath, relative to the volume.
+func (lw *localWriter) List(ctx context.Context, basePath string) ([]string, error) {
+	name, err := util.LocalBackupPath(lw.dir, basePath)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Dir(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	prefix := filepath.Base(name)
+	paths := []string{}
+	for _, e := range entries {
+		if e.Type().IsRegular() && strings.HasPrefix(e.Name(), prefix) {
+			paths = append(paths, path.Join(path.Dir(basePath), e.Name()))
+		}
+	}
+	return paths, nil
+}
+
+func (lw *localWriter) Delete(ctx context.Context, p string) error {
+	name, err := util.LocalBackupPath(lw.dir, p)
+	if err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
@@ -0,0 +1,70 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestLocalWriter(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	w := NewLocalWriter(dir)
+
+	paths := []string{"mycluster/etcd.backup_v1", "mycluster/etcd.backup_v2", "mycluster/other.backup"}
+	for _, p := range paths {
+		n, err := w.Write(ctx, p, strings.NewReader(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != int64(len(p)) {
+			t.Errorf("written get=%d, want=%d", n, len(p))
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(dir, paths[0]))
+	if err != nil || string(b) != paths[0] {
+		t.Errorf("content get=%q (%v), want=%q", b, err, paths[0])
+	}
+
+	got, err := w.List(ctx, "mycluster/etcd.backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	if want := paths[:2]; !reflect.DeepEqual(got, want) {
+		t.Errorf("list get=%v, want=%v", got, want)
+	}
+
+	if err := w.Delete(ctx, paths[0]); err != nil {
+		t.Fatal(err)
+	}
+	got, err = w.List(ctx, "mycluster/etcd.backup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := paths[1:2]; !reflect.DeepEqual(got, want) {
+		t.Errorf("list after delete get=%v, want=%v", got, want)
+	}
+
+	if _, err := w.Write(ctx, "../etcd.backup", strings.NewReader("")); err == nil {
+		t.Errorf("expected error writing outside of the volume")
+	}
+}
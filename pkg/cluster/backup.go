@@ -0,0 +1,85 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// reconcileBackup makes sure the EtcdBackup of the backup policy of the cluster exists and matches the policy.
+// If the cluster has no backup policy, it deletes the EtcdBackup the operator created for the cluster.
+// EtcdBackups that are not owned by the cluster are left alone.
+func (c *Cluster) reconcileBackup(ctx context.Context) error {
+	name := api.EtcdBackupName(c.cluster.Name)
+	backups := c.config.EtcdCRCli.EtcdV1beta2().EtcdBackups(c.cluster.Namespace)
+	eb, err := backups.Get(ctx, name, metav1.GetOptions{})
+	if k8sutil.IsKubernetesResourceNotFoundError(err) {
+		if c.cluster.Spec.Backup == nil {
+			return nil
+		}
+		c.logger.Infof("creating EtcdBackup (%s)", name)
+		_, err = backups.Create(ctx, c.newBackup(name), metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to create EtcdBackup (%s): %v", name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get EtcdBackup (%s): %v", name, err)
+	}
+	if !metav1.IsControlledBy(eb, c.cluster) {
+		return fmt.Errorf("EtcdBackup (%s) exists and is not owned by the cluster", name)
+	}
+
+	if c.cluster.Spec.Backup == nil {
+		c.logger.Infof("deleting EtcdBackup (%s)", name)
+		err := backups.Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !k8sutil.IsKubernetesResourceNotFoundError(err) {
+			return fmt.Errorf("failed to delete EtcdBackup (%s): %v", name, err)
+		}
+		return nil
+	}
+	spec := c.cluster.Spec.Backup.BackupSpec(c.cluster.Name)
+	if reflect.DeepEqual(eb.Spec, spec) {
+		return nil
+	}
+	c.logger.Infof("updating EtcdBackup (%s)", name)
+	eb = eb.DeepCopy()
+	eb.Spec = spec
+	_, err = backups.Update(ctx, eb, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update EtcdBackup (%s): %v", name, err)
+	}
+	return nil
+}
+
+func (c *Cluster) newBackup(name string) *api.EtcdBackup {
+	return &api.EtcdBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       c.cluster.Namespace,
+			Labels:          k8sutil.LabelsForCluster(c.cluster.Name),
+			OwnerReferences: []metav1.OwnerReference{c.cluster.AsOwner()},
+		},
+		Spec: c.cluster.Spec.Backup.BackupSpec(c.cluster.Name),
+	}
+}
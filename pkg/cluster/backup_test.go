@@ -0,0 +1,110 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/generated/clientset/versioned/fake"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestReconcileBackup(t *testing.T) {
+	ctx := context.Background()
+	crcli := fake.NewSimpleClientset()
+	c := &Cluster{
+		logger: logrus.WithField("pkg", "cluster"),
+		config: Config{EtcdCRCli: crcli},
+		cluster: &api.EtcdCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault, UID: "1234"},
+			Spec: api.ClusterSpec{
+				Backup: &api.ClusterBackupPolicy{
+					StorageType:  api.BackupStorageTypeS3,
+					BackupPolicy: &api.BackupPolicy{Schedule: "@daily"},
+					BackupSource: api.BackupSource{S3: &api.S3BackupSource{Path: "bucket/test.backup", AWSSecret: "aws"}},
+				},
+			},
+		},
+	}
+	backups := crcli.EtcdV1beta2().EtcdBackups(metav1.NamespaceDefault)
+
+	if err := c.reconcileBackup(ctx); err != nil {
+		t.Fatal(err)
+	}
+	eb, err := backups.Get(ctx, "test-backup", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !metav1.IsControlledBy(eb, c.cluster) {
+		t.Errorf("expected the backup to be owned by the cluster, owners: %v", eb.OwnerReferences)
+	}
+	if eb.Spec.EtcdClusterRef == nil || eb.Spec.EtcdClusterRef.Name != "test" || eb.Labels["etcd_cluster"] != "test" {
+		t.Errorf("expected the backup to reference the cluster: %+v", eb)
+	}
+
+	c.cluster.Spec.Backup.BackupPolicy.Schedule = "@hourly"
+	if err := c.reconcileBackup(ctx); err != nil {
+		t.Fatal(err)
+	}
+	eb, err = backups.Get(ctx, "test-backup", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eb.Spec.BackupPolicy.Schedule != "@hourly" {
+		t.Errorf("schedule get=%s, want=@hourly", eb.Spec.BackupPolicy.Schedule)
+	}
+
+	c.cluster.Spec.Backup = nil
+	if err := c.reconcileBackup(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := backups.Get(ctx, "test-backup", metav1.GetOptions{}); !k8sutil.IsKubernetesResourceNotFoundError(err) {
+		t.Errorf("expected the backup to be deleted, got: %v", err)
+	}
+}
+
+func TestReconcileBackupNotOwned(t *testing.T) {
+	ctx := context.Background()
+	other := &api.EtcdBackup{ObjectMeta: metav1.ObjectMeta{Name: "test-backup", Namespace: metav1.NamespaceDefault}}
+	crcli := fake.NewSimpleClientset(other)
+	c := &Cluster{
+		logger: logrus.WithField("pkg", "cluster"),
+		config: Config{EtcdCRCli: crcli},
+		cluster: &api.EtcdCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault, UID: "1234"},
+		},
+	}
+
+	// the backup is not deleted, nor taken over
+	if err := c.reconcileBackup(ctx); err == nil {
+		t.Error("expected an error for a backup that is not owned by the cluster")
+	}
+	c.cluster.Spec.Backup = &api.ClusterBackupPolicy{StorageType: api.BackupStorageTypeS3}
+	if err := c.reconcileBackup(ctx); err == nil {
+		t.Error("expected an error for a backup that is not owned by the cluster")
+	}
+	eb, err := crcli.EtcdV1beta2().EtcdBackups(metav1.NamespaceDefault).Get(ctx, "test-backup", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eb.Spec.EtcdClusterRef != nil {
+		t.Errorf("expected the backup to be left alone: %+v", eb.Spec)
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"time"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/retryutil"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var certificateGVR = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1", Resource: "certificates"}
+
+// reconcileCertManagerTLS makes sure the cert-manager Certificates of the cluster exist and match the spec,
+// and reports their expiry in the status. It returns whether all of them are ready.
+func (c *Cluster) reconcileCertManagerTLS(ctx context.Context) (bool, error) {
+	certs := c.config.DynamicCli.Resource(certificateGVR).Namespace(c.cluster.Namespace)
+	ready := true
+	var tlsStatus api.TLSStatus
+	for _, cc := range c.clusterCerts() {
+		desired := c.newCertificate(cc)
+		cert, err := certs.Get(ctx, cc.secret, metav1.GetOptions{})
+		if k8sutil.IsKubernetesResourceNotFoundError(err) {
+			c.logger.Infof("creating cert-manager certificate (%s)", cc.secret)
+			cert, err = certs.Create(ctx, desired, metav1.CreateOptions{})
+		} else if err == nil && updateCertificateSpec(cert, desired) {
+			// e.g. the issuer of the cluster was changed, or the SANs changed with a new operator version.
+			// cert-manager then issues a new certificate, and the members are replaced like on a renewal.
+			c.logger.Infof("updating cert-manager certificate (%s)", cc.secret)
+			cert, err = certs.Update(ctx, cert, metav1.UpdateOptions{})
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to reconcile cert-manager certificate (%s): %v", cc.secret, err)
+		}
+		if !isCertificateReady(cert) {
+			c.logger.Infof("cert-manager certificate (%s) is not ready", cc.secret)
+			ready = false
+		}
+		earliestStatusTime(cert, &tlsStatus.ExpirationDate, "notAfter")
+		earliestStatusTime(cert, &tlsStatus.RenewalDate, "renewalTime")
+	}
+	c.status.TLS = &tlsStatus
+	return ready, nil
+}
+
+func (c *Cluster) newCertificate(cc clusterCert) *unstructured.Unstructured {
+	ref := c.cluster.Spec.TLS.CertManager.IssuerRef
+	issuerRef := map[string]interface{}{"name": ref.Name}
+	if len(ref.Kind) != 0 {
+		issuerRef["kind"] = ref.Kind
+	}
+	if len(ref.Group) != 0 {
+		issuerRef["group"] = ref.Group
+	}
+
+	usages := []interface{}{"digital signature", "key encipherment"}
+	for _, u := range cc.cfg.ExtKeyUsage {
+		switch u {
+		case x509.ExtKeyUsageServerAuth:
+			usages = append(usages, "server auth")
+		case x509.ExtKeyUsageClientAuth:
+			usages = append(usages, "client auth")
+		}
+	}
+	spec := map[string]interface{}{
+		"secretName": cc.secret,
+		"commonName": cc.cfg.CommonName,
+		"usages":     usages,
+		"issuerRef":  issuerRef,
+		"secretTemplate": map[string]interface{}{
+			"labels": toInterfaceMap(k8sutil.LabelsForCluster(c.cluster.Name)),
+		},
+	}
+	if len(cc.cfg.DNSNames) != 0 {
+		dnsNames := make([]interface{}, 0, len(cc.cfg.DNSNames))
+		for _, n := range cc.cfg.DNSNames {
+			dnsNames = append(dnsNames, n)
+		}
+		spec["dnsNames"] = dnsNames
+	}
+	if len(cc.cfg.IPAddresses) != 0 {
+		ips := make([]interface{}, 0, len(cc.cfg.IPAddresses))
+		for _, ip := range cc.cfg.IPAddresses {
+			ips = append(ips, ip.String())
+		}
+		spec["ipAddresses"] = ips
+	}
+
+	cert := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "Certificate",
+		"spec":       spec,
+	}}
+	cert.SetName(cc.secret)
+	cert.SetNamespace(c.cluster.Namespace)
+	cert.SetLabels(k8sutil.LabelsForCluster(c.cluster.Name))
+	cert.SetOwnerReferences([]metav1.OwnerReference{c.cluster.AsOwner()})
+	return cert
+}
+
+// updateCertificateSpec sets the spec fields of cert that differ from those of desired.
+// Fields that are not set by the operator are left alone. It returns whether cert was changed.
+func updateCertificateSpec(cert, desired *unstructured.Unstructured) bool {
+	spec, _, _ := unstructured.NestedMap(cert.Object, "spec")
+	if spec == nil {
+		spec = map[string]interface{}{}
+	}
+	desiredSpec, _, _ := unstructured.NestedMap(desired.Object, "spec")
+	changed := false
+	// dnsNames and ipAddresses are left out of the desired spec when empty, so they are compared explicitly.
+	for _, field := range []string{"secretName", "commonName", "usages", "issuerRef", "secretTemplate", "dnsNames", "ipAddresses"} {
+		v, ok := desiredSpec[field]
+		if !ok {
+			if _, exists := spec[field]; exists {
+				delete(spec, field)
+				changed = true
+			}
+			continue
+		}
+		if !equality.Semantic.DeepEqual(spec[field], v) {
+			spec[field] = v
+			changed = true
+		}
+	}
+	if changed {
+		cert.Object["spec"] = spec
+	}
+	return changed
+}
+
+// waitCertManagerTLS waits for the cert-manager Certificates of the cluster to be ready,
+// so that the members can be bootstrapped with them.
+func (c *Cluster) waitCertManagerTLS(ctx context.Context) error {
+	return retryutil.Retry(10*time.Second, 30, func() (bool, error) {
+		ready, err := c.reconcileCertManagerTLS(ctx)
+		if err != nil {
+			c.logger.Warningf("cert-manager TLS setup failed: %v", err)
+			return false, nil
+		}
+		return ready, nil
+	})
+}
+
+func isCertificateReady(cert *unstructured.Unstructured) bool {
+	conds, _, _ := unstructured.NestedSlice(cert.Object, "status", "conditions")
+	for _, cond := range conds {
+		m, ok := cond.(map[string]interface{})
+		if ok && m["type"] == "Ready" {
+			return m["status"] == "True"
+		}
+	}
+	return false
+}
+
+// earliestStatusTime sets t to the time in the status field of the certificate if it is earlier.
+func earliestStatusTime(cert *unstructured.Unstructured, t *metav1.Time, field string) {
+	s, _, _ := unstructured.NestedString(cert.Object, "status", field)
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return
+	}
+	if t.IsZero() || parsed.Before(t.Time) {
+		*t = metav1.NewTime(parsed)
+	}
+}
+
+func toInterfaceMap(m map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
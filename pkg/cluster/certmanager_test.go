@@ -0,0 +1,166 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestReconcileCertManagerTLS(t *testing.T) {
+	ctx := context.Background()
+	dynamiccli := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{certificateGVR: "CertificateList"})
+	c := &Cluster{
+		logger: logrus.WithField("pkg", "cluster"),
+		config: Config{DynamicCli: dynamiccli},
+		cluster: &api.EtcdCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+			Spec: api.ClusterSpec{
+				TLS: &api.TLSPolicy{CertManager: &api.CertManagerTLS{
+					IssuerRef: api.CertManagerIssuerRef{Name: "etcd-ca", Kind: "ClusterIssuer"},
+				}},
+			},
+		},
+	}
+
+	ready, err := c.reconcileCertManagerTLS(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ready {
+		t.Error("expected new certificates not to be ready")
+	}
+
+	certs := dynamiccli.Resource(certificateGVR).Namespace(metav1.NamespaceDefault)
+	peer, err := certs.Get(ctx, "test-peer-tls", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dnsNames, _, _ := unstructured.NestedStringSlice(peer.Object, "spec", "dnsNames")
+	if len(dnsNames) != 1 || dnsNames[0] != "*.test.default.svc" {
+		t.Errorf("unexpected peer dnsNames: %v", dnsNames)
+	}
+	kind, _, _ := unstructured.NestedString(peer.Object, "spec", "issuerRef", "kind")
+	if kind != "ClusterIssuer" {
+		t.Errorf("unexpected issuer kind: %q", kind)
+	}
+
+	for _, name := range []string{"test-peer-tls", "test-server-tls", "test-operator-tls"} {
+		cert, err := certs.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert.Object["status"] = map[string]interface{}{
+			"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+			"notAfter":   "2027-01-02T03:04:05Z",
+		}
+		if _, err := certs.Update(ctx, cert, metav1.UpdateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ready, err = c.reconcileCertManagerTLS(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ready {
+		t.Error("expected certificates to be ready")
+	}
+	if c.status.TLS == nil || c.status.TLS.ExpirationDate.Year() != 2027 {
+		t.Errorf("unexpected TLS status: %+v", c.status.TLS)
+	}
+}
+
+func TestReconcileCertManagerTLSDrift(t *testing.T) {
+	ctx := context.Background()
+	dynamiccli := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{certificateGVR: "CertificateList"})
+	c := &Cluster{
+		logger: logrus.WithField("pkg", "cluster"),
+		config: Config{DynamicCli: dynamiccli},
+		cluster: &api.EtcdCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+			Spec: api.ClusterSpec{
+				TLS: &api.TLSPolicy{CertManager: &api.CertManagerTLS{
+					IssuerRef: api.CertManagerIssuerRef{Name: "etcd-ca", Kind: "ClusterIssuer"},
+				}},
+			},
+		},
+	}
+	if _, err := c.reconcileCertManagerTLS(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	certs := dynamiccli.Resource(certificateGVR).Namespace(metav1.NamespaceDefault)
+	peer, err := certs.Get(ctx, "test-peer-tls", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Fields the operator does not set are kept.
+	unstructured.SetNestedField(peer.Object, "4320h", "spec", "duration")
+	unstructured.SetNestedStringSlice(peer.Object, []string{"etcd.example.com"}, "spec", "dnsNames")
+	if _, err := certs.Update(ctx, peer, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	c.cluster.Spec.TLS.CertManager.IssuerRef = api.CertManagerIssuerRef{Name: "other-ca"}
+	dynamiccli.ClearActions()
+
+	if _, err := c.reconcileCertManagerTLS(ctx); err != nil {
+		t.Fatal(err)
+	}
+	updates := 0
+	for _, a := range dynamiccli.Actions() {
+		if a.GetVerb() == "update" {
+			updates++
+		}
+	}
+	if updates != 3 {
+		t.Errorf("updated certificates get=%d, want=3", updates)
+	}
+	peer, err = certs.Get(ctx, "test-peer-tls", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuerRef, _, _ := unstructured.NestedStringMap(peer.Object, "spec", "issuerRef")
+	if len(issuerRef) != 1 || issuerRef["name"] != "other-ca" {
+		t.Errorf("unexpected issuerRef: %v", issuerRef)
+	}
+	dnsNames, _, _ := unstructured.NestedStringSlice(peer.Object, "spec", "dnsNames")
+	if len(dnsNames) != 1 || dnsNames[0] != "*.test.default.svc" {
+		t.Errorf("unexpected peer dnsNames: %v", dnsNames)
+	}
+	if d, _, _ := unstructured.NestedString(peer.Object, "spec", "duration"); d != "4320h" {
+		t.Errorf("duration get=%q, want=4320h", d)
+	}
+
+	dynamiccli.ClearActions()
+	if _, err := c.reconcileCertManagerTLS(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range dynamiccli.Actions() {
+		if a.GetVerb() == "update" {
+			t.Errorf("unexpected update of certificates in sync: %v", a)
+		}
+	}
+}
@@ -35,6 +35,8 @@ import (
 	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 )
@@ -61,6 +63,8 @@ type Config struct {
 
 	KubeCli   kubernetes.Interface
 	EtcdCRCli versioned.Interface
+	// DynamicCli is used for resources of other operators, like cert-manager Certificates.
+	DynamicCli dynamic.Interface
 }
 
 type Cluster struct {
@@ -69,6 +73,8 @@ type Cluster struct {
 	config Config
 
 	cluster *api.EtcdCluster
+	// uid is the UID of the EtcdCluster CR the cluster is created for.
+	uid types.UID
 
 	// in memory state of the cluster
 	// status is the source of truth after Cluster struct is materialized.
@@ -82,7 +88,22 @@ type Cluster struct {
 	// process runs in.
 	members etcdutil.MemberSet
 
+	// memberRecreations counts how many times a dead member has been recreated on its PVC
+	// without becoming ready.
+	memberRecreations map[string]int
+
+	// memberRecovery is set while the cluster is recovered from one of its members.
+	memberRecovery *memberRecovery
+	// backupRecovery is set while the cluster is handed over to the restore operator.
+	backupRecovery *backupRecovery
+	// backupRecoveryFailure is why the last recovery from a backup failed, the cluster does not start another one then.
+	backupRecoveryFailure string
+	// downgrading is set while the operator downgrades the cluster with the downgrade API of etcd.
+	downgrading bool
+
 	tlsConfig *tls.Config
+	// operatorTLSHash is the hash of the operator secret tlsConfig was loaded from.
+	operatorTLSHash string
 
 	eventsCli corev1.EventInterface
 }
@@ -97,10 +118,13 @@ func New(config Config, cl *api.EtcdCluster) *Cluster {
 		logger:    lg,
 		config:    config,
 		cluster:   cl,
+		uid:       cl.UID,
 		eventCh:   make(chan *clusterEvent, 100),
 		stopCh:    make(chan struct{}),
 		status:    *(cl.Status.DeepCopy()),
 		eventsCli: config.KubeCli.CoreV1().Events(cl.Namespace),
+
+		memberRecreations: make(map[string]int),
 	}
 
 	go func() {
@@ -135,14 +159,22 @@ func (c *Cluster) setup(ctx context.Context) error {
 		return fmt.Errorf("unexpected cluster phase: %s", c.status.Phase)
 	}
 
+	if c.cluster.Spec.TLS.IsOperatorManaged() {
+		if _, err := c.reconcileOperatorTLS(ctx); err != nil {
+			return fmt.Errorf("failed to issue TLS certificates: %v", err)
+		}
+	}
+	if c.cluster.Spec.TLS.IsCertManagerManaged() {
+		if err := c.waitCertManagerTLS(ctx); err != nil {
+			return fmt.Errorf("cert-manager certificates are not ready: %v", err)
+		}
+	}
+
 	if c.isSecureClient() {
 		timeoutRetry := 10 * time.Second
 		timeoutInterval := 30
 		for i := 1; ; i++ {
-			d, err := k8sutil.GetTLSDataFromSecret(ctx, c.config.KubeCli, c.cluster.Namespace, c.cluster.Spec.TLS.Static.OperatorSecret)
-			if err == nil {
-				c.tlsConfig, err = etcdutil.NewTLSConfig(d.CertData, d.KeyData, d.CAData)
-			}
+			err := c.loadOperatorTLSConfig(ctx)
 			if err != nil {
 				if i > timeoutInterval {
 					return err
@@ -184,6 +216,11 @@ func (c *Cluster) prepareSeedMember(ctx context.Context) error {
 	return nil
 }
 
+// UID returns the UID of the EtcdCluster CR the cluster is created for.
+func (c *Cluster) UID() types.UID {
+	return c.uid
+}
+
 func (c *Cluster) Delete() {
 	c.logger.Info("cluster is deleted by user")
 	close(c.stopCh)
@@ -245,6 +282,11 @@ func (c *Cluster) run(ctx context.Context) {
 				c.status.Control()
 			}
 
+			if c.backupRecovery != nil && c.reconcileBackupRecovery(ctx) {
+				c.logger.Infof("cluster is being restored from backup, skipping reconciliation")
+				continue
+			}
+
 			running, pending, err := c.pollPods(ctx)
 			if err != nil {
 				c.logger.Errorf("fail to poll pods: %v", err)
@@ -258,14 +300,14 @@ func (c *Cluster) run(ctx context.Context) {
 				reconcileFailed.WithLabelValues("not all pods are running").Inc()
 				continue
 			}
-			if len(running) == 0 {
-				// All pods are dead, cluster cannot recover so return error
-				rerr = errAllPodsDead
+			if len(running) == 0 && !c.canRecreateAllMembers() {
+				// All pods are dead, the cluster can only be recovered from a backup
+				rerr = c.recoverFromQuorumLoss(ctx, nil, errAllPodsDead)
 				break
 			}
 
 			// On controller restore, we could have "members == nil"
-			if rerr != nil || c.members == nil {
+			if len(running) > 0 && (rerr != nil || c.members == nil) {
 				rerr = c.updateMembers(podsToMemberSet(running, c.isSecureClient()))
 				if rerr != nil {
 					c.logger.Errorf("failed to update members: %v", rerr)
@@ -302,6 +344,12 @@ func (c *Cluster) handleUpdateEvent(ctx context.Context, event *clusterEvent) er
 	oldSpec := c.cluster.Spec.DeepCopy()
 	c.cluster = event.cluster
 
+	if oldSpec.Backup != nil && event.cluster.Spec.Backup == nil {
+		if err := c.reconcileBackup(ctx); err != nil {
+			c.logger.Errorf("failed to delete backup: %v", err)
+		}
+	}
+
 	if isSpecEqual(event.cluster.Spec, *oldSpec) {
 		// We have some fields that once created could not be mutated.
 		if !reflect.DeepEqual(event.cluster.Spec, *oldSpec) {
@@ -320,6 +368,9 @@ func isSpecEqual(s1, s2 api.ClusterSpec) bool {
 	if s1.Size != s2.Size || s1.Paused != s2.Paused || s1.Version != s2.Version {
 		return false
 	}
+	if k8sutil.PodTemplateHash(s1) != k8sutil.PodTemplateHash(s2) {
+		return false
+	}
 	return true
 }
 
@@ -376,6 +427,12 @@ func (c *Cluster) setupServices(ctx context.Context) error {
 	return k8sutil.CreatePeerService(ctx, c.config.KubeCli, c.cluster.Name, c.cluster.Namespace, c.cluster.AsOwner(), c.isSecureClient(), c.cluster.Spec.Service)
 }
 
+// canRecreateAllMembers tells whether the members can be brought back on their PVCs
+// after all of their pods are gone.
+func (c *Cluster) canRecreateAllMembers() bool {
+	return (c.isStableStorageEnabled() || c.memberRecovery != nil) && c.members.Size() > 0
+}
+
 func (c *Cluster) isPodPVEnabled() bool {
 	if podPolicy := c.cluster.Spec.Pod; podPolicy != nil {
 		return podPolicy.PersistentVolumeClaimSpec != nil
@@ -473,7 +530,7 @@ func (c *Cluster) updateCRStatus(ctx context.Context) error {
 
 	newCluster := c.cluster
 	newCluster.Status = c.status
-	newCluster, err := c.config.EtcdCRCli.EtcdV1beta2().EtcdClusters(c.cluster.Namespace).Update(ctx, c.cluster, metav1.UpdateOptions{})
+	newCluster, err := c.config.EtcdCRCli.EtcdV1beta2().EtcdClusters(c.cluster.Namespace).UpdateStatus(ctx, c.cluster, metav1.UpdateOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to update CR status: %v", err)
 	}
@@ -0,0 +1,100 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/on2itsecurity/etcd-operator/pkg/util/etcdutil"
+
+	"github.com/coreos/go-semver/semver"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// downgradeOneMember downgrades the cluster to the previous minor version with the downgrade API of etcd.
+// The downgrade is validated and enabled first. etcd then migrates the storage of the members to the
+// target version, and once all of them are migrated the members are replaced one at a time.
+func (c *Cluster) downgradeOneMember(ctx context.Context, memberName, version string) error {
+	target, err := downgradeTarget(version)
+	if err != nil {
+		return err
+	}
+
+	st, err := etcdutil.MemberStatus(c.members.PickOne().ClientURL(), c.tlsConfig)
+	if err != nil {
+		return fmt.Errorf("failed to get downgrade status: %v", err)
+	}
+	if di := st.DowngradeInfo; di == nil || !di.Enabled {
+		if err := etcdutil.Downgrade(c.members.ClientURLs(), c.tlsConfig, clientv3.DowngradeValidate, target); err != nil {
+			return fmt.Errorf("downgrade to %s is not valid: %v", target, err)
+		}
+		if err := etcdutil.Downgrade(c.members.ClientURLs(), c.tlsConfig, clientv3.DowngradeEnable, target); err != nil {
+			return fmt.Errorf("failed to enable downgrade to %s: %v", target, err)
+		}
+		c.logger.Infof("enabled downgrade of the cluster to %s", target)
+		c.downgrading = true
+		// The members are replaced once etcd has migrated their storage.
+		return nil
+	}
+	c.downgrading = true
+
+	for _, m := range c.members {
+		st, err := etcdutil.MemberStatus(m.ClientURL(), c.tlsConfig)
+		if err != nil {
+			return fmt.Errorf("failed to get storage version of member (%s): %v", m.Name, err)
+		}
+		if !isStorageDowngraded(st.StorageVersion, target) {
+			c.logger.Infof("waiting for the storage of member (%s) to be migrated from %s to %s", m.Name, st.StorageVersion, target)
+			return nil
+		}
+	}
+	return c.upgradeOneMember(ctx, memberName)
+}
+
+// finishDowngrade cancels the downgrade mode of the cluster once all members run the target version.
+// etcd usually ends the downgrade by itself by then, so failures are only logged.
+func (c *Cluster) finishDowngrade() {
+	c.downgrading = false
+	if err := etcdutil.Downgrade(c.members.ClientURLs(), c.tlsConfig, clientv3.DowngradeCancel, ""); err != nil {
+		c.logger.Infof("cancelling downgrade: %v", err)
+		return
+	}
+	c.logger.Infof("cancelled downgrade, all members are downgraded")
+}
+
+// downgradeTarget returns the "<major>.<minor>" target of the downgrade API of etcd for version.
+func downgradeTarget(version string) (string, error) {
+	v, err := semver.NewVersion(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return "", fmt.Errorf("invalid version %q: %v", version, err)
+	}
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor), nil
+}
+
+// isStorageDowngraded tells whether the storage version of a member is not newer than the downgrade target.
+// Members that do not report their storage version are considered migrated.
+func isStorageDowngraded(storageVersion, target string) bool {
+	sv, err := semver.NewVersion(storageVersion)
+	if err != nil {
+		return true
+	}
+	tv, err := semver.NewVersion(target + ".0")
+	if err != nil {
+		return true
+	}
+	return sv.Major < tv.Major || (sv.Major == tv.Major && sv.Minor <= tv.Minor)
+}
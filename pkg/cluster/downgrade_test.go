@@ -0,0 +1,100 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/etcdutil"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDowngradeTarget(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{version: "v3.5.21", want: "3.5"},
+		{version: "3.5.0", want: "3.5"},
+		{version: "latest", wantErr: true},
+	}
+	for i, tt := range tests {
+		got, err := downgradeTarget(tt.version)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("#%d: get=%s, want=%s", i, got, tt.want)
+		}
+	}
+}
+
+func TestIsStorageDowngraded(t *testing.T) {
+	tests := []struct {
+		storageVersion string
+		want           bool
+	}{
+		{"3.6.0", false},
+		{"3.5.0", true},
+		{"4.0.0", false},
+		// not reported
+		{"", true},
+	}
+	for i, tt := range tests {
+		if got := isStorageDowngraded(tt.storageVersion, "3.5"); got != tt.want {
+			t.Errorf("#%d: storage version %q get=%v, want=%v", i, tt.storageVersion, got, tt.want)
+		}
+	}
+}
+
+func TestReconcileRejectsUnsupportedDowngrade(t *testing.T) {
+	sp := api.ClusterSpec{Size: 1, Version: "3.4.0"}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-a", Namespace: metav1.NamespaceDefault, Annotations: map[string]string{}}}
+	pod.Annotations["etcd.pod-template"] = k8sutil.PodTemplateHash(sp)
+	k8sutil.SetEtcdVersion(pod, "3.6.10")
+	c := &Cluster{
+		logger: logrus.WithField("pkg", "cluster"),
+		config: Config{KubeCli: fake.NewSimpleClientset()},
+		cluster: &api.EtcdCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+			Spec:       sp,
+		},
+		members: etcdutil.MemberSet{"test-a": &etcdutil.Member{Name: "test-a", Namespace: metav1.NamespaceDefault}},
+	}
+	c.status.CurrentVersion = "3.6.10"
+
+	if err := c.reconcile(context.Background(), []*v1.Pod{pod}); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.status.TargetVersion) != 0 {
+		t.Errorf("target version get=%s, want none for a rejected downgrade", c.status.TargetVersion)
+	}
+	var rejected bool
+	for _, cond := range c.status.Conditions {
+		rejected = rejected || (cond.Type == api.ClusterConditionUpgrading && cond.Status == v1.ConditionFalse)
+	}
+	if !rejected {
+		t.Errorf("conditions get=%+v, want the upgrade rejected", c.status.Conditions)
+	}
+}
@@ -24,14 +24,24 @@ var (
 
 type fatalError struct {
 	reason string
+	cause  error
 }
 
 func (fe *fatalError) Error() string {
 	return fe.reason
 }
 
+func (fe *fatalError) Unwrap() error {
+	return fe.cause
+}
+
 func newFatalError(reason string) *fatalError {
-	return &fatalError{reason}
+	return &fatalError{reason: reason}
+}
+
+// wrapFatalError returns a fatal error that wraps cause, with reason appended to its message.
+func wrapFatalError(cause error, reason string) *fatalError {
+	return &fatalError{reason: cause.Error() + ": " + reason, cause: cause}
 }
 
 func isFatalError(err error) bool {
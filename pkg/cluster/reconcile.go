@@ -34,6 +34,7 @@ var ErrLostQuorum = newFatalError("lost quorum")
 
 // reconcile reconciles cluster current state to desired state specified by spec.
 // - it tries to reconcile the cluster to desired size.
+// - if pods do not match the pod spec or use renewed TLS certificates, it replaces outdated members one by one.
 // - if the cluster needs for upgrade, it tries to upgrade old member one by one.
 func (c *Cluster) reconcile(ctx context.Context, pods []*v1.Pod) error {
 	c.logger.Infoln("Start reconciling")
@@ -43,6 +44,36 @@ func (c *Cluster) reconcile(ctx context.Context, pods []*v1.Pod) error {
 		c.status.Size = c.members.Size()
 	}()
 
+	c.resetMemberRecreations(pods)
+
+	if c.cluster.Spec.TLS.IsOperatorManaged() {
+		if _, err := c.reconcileOperatorTLS(ctx); err != nil {
+			return err
+		}
+	}
+	if c.cluster.Spec.TLS.IsCertManagerManaged() {
+		// Members keep using their current certificates until cert-manager has issued new ones.
+		if _, err := c.reconcileCertManagerTLS(ctx); err != nil {
+			return err
+		}
+	}
+	if c.cluster.Spec.TLS.IsManaged() {
+		if err := c.loadOperatorTLSConfig(ctx); err != nil {
+			return fmt.Errorf("failed to reload operator TLS config: %v", err)
+		}
+	}
+
+	if c.cluster.Spec.Backup != nil {
+		// A backup that cannot be maintained does not block the reconciliation of the members.
+		if err := c.reconcileBackup(ctx); err != nil {
+			c.logger.Errorf("failed to reconcile backup: %v", err)
+		}
+	}
+
+	if c.memberRecovery != nil {
+		return c.reconcileMemberRecovery(ctx, pods)
+	}
+
 	sp := c.cluster.Spec
 	running := podsToMemberSet(pods, c.isSecureClient())
 	if !running.IsEqual(c.members) || c.members.Size() != sp.Size {
@@ -50,16 +81,48 @@ func (c *Cluster) reconcile(ctx context.Context, pods []*v1.Pod) error {
 	}
 	c.status.ClearCondition(api.ClusterConditionScaling)
 
+	outdated, err := c.outdatedMembers(ctx, pods)
+	if err != nil {
+		return err
+	}
+	if outdated.Size() > 0 {
+		c.status.SetRollingCondition(len(pods)-outdated.Size(), sp.Size)
+		return c.rollOneMember(ctx, pods, outdated)
+	}
+	c.status.ClearCondition(api.ClusterConditionRolling)
+
 	if needUpgrade(pods, sp) {
+		pod := pickOneOldMember(pods, sp.Version)
+		from := k8sutil.GetEtcdVersion(pod)
+		if err := api.ValidateVersionChange(from, sp.Version); err != nil {
+			// The members keep their version until the version is changed to a supported one.
+			c.status.SetUpgradeRejectedCondition(err.Error())
+			c.logger.Warningf("not changing the version of the cluster: %v", err)
+			return nil
+		}
 		c.status.UpgradeVersionTo(sp.Version)
+		// The upgrade waits until the snapshot is saved, failures are retried by the next reconciliation.
+		if c.needBackupBeforeUpgrade(sp.Version) {
+			if err := c.backupBeforeUpgrade(ctx, sp.Version); err != nil {
+				return err
+			}
+		}
 
-		m := pickOneOldMember(pods, sp.Version)
-		return c.upgradeOneMember(ctx, m.Name)
+		if api.IsMinorDowngrade(from, sp.Version) {
+			c.status.SetDowngradingCondition(sp.Version)
+			return c.downgradeOneMember(ctx, pod.Name, sp.Version)
+		}
+		c.status.SetUpgradingCondition(sp.Version)
+		return c.upgradeOneMember(ctx, pod.Name)
 	}
 	c.status.ClearCondition(api.ClusterConditionUpgrading)
+	if c.downgrading {
+		c.finishDowngrade()
+	}
 
 	c.status.SetVersion(sp.Version)
 	c.status.SetReadyCondition()
+	c.status.ObservedGeneration = c.cluster.Generation
 
 	return nil
 }
@@ -71,8 +134,9 @@ func (c *Cluster) reconcile(ctx context.Context, pods []*v1.Pod) error {
 // 1. Remove all pods from running set that does not belong to member set.
 // 2. L consist of remaining pods of runnings
 // 3. If L = members, the current state matches the membership state. END.
-// 4. If len(L) < len(members)/2 + 1, return quorum lost error.
-// 5. Add one missing member. END.
+// 4. If stable storage is enabled and a dead member still has its PVC, recreate its pod. END.
+// 5. If len(L) < len(members)/2 + 1, recover as the recovery policy allows or return quorum lost error.
+// 6. Remove one dead member. END.
 func (c *Cluster) reconcileMembers(ctx context.Context, running etcdutil.MemberSet) error {
 	c.logger.Infof("running members: %s", running)
 	c.logger.Infof("cluster membership: %s", c.members)
@@ -92,13 +156,26 @@ func (c *Cluster) reconcileMembers(ctx context.Context, running etcdutil.MemberS
 		return c.resize(ctx)
 	}
 
+	dead := c.members.Diff(L)
+	if c.isStableStorageEnabled() {
+		// Recreating a member on its PVC does not change the membership,
+		// so it is also safe to do while the cluster has lost quorum.
+		m, pvc, err := c.pickRecreatableMember(ctx, dead)
+		if err != nil {
+			return err
+		}
+		if m != nil {
+			return c.recreateDeadMember(ctx, m, pvc)
+		}
+	}
+
 	if L.Size() < c.members.Size()/2+1 {
-		return ErrLostQuorum
+		return c.recoverFromQuorumLoss(ctx, L, ErrLostQuorum)
 	}
 
 	c.logger.Infof("removing one dead member")
 	// remove dead members that doesn't have any running pods before doing resizing.
-	return c.removeDeadMember(ctx, c.members.Diff(L).PickOne())
+	return c.removeDeadMember(ctx, dead.PickOne())
 }
 
 func (c *Cluster) resize(ctx context.Context) error {
@@ -107,6 +184,7 @@ func (c *Cluster) resize(ctx context.Context) error {
 	}
 
 	if c.members.Size() < c.cluster.Spec.Size {
+		c.status.SetScalingUpCondition(c.members.Size(), c.cluster.Spec.Size)
 		return c.addOneMember(ctx)
 	}
 
@@ -114,8 +192,6 @@ func (c *Cluster) resize(ctx context.Context) error {
 }
 
 func (c *Cluster) addOneMember(ctx context.Context) error {
-	c.status.SetScalingUpCondition(c.members.Size(), c.cluster.Spec.Size)
-
 	cfg := clientv3.Config{
 		Endpoints:   c.members.ClientURLs(),
 		DialTimeout: constants.DefaultDialTimeout,
@@ -148,7 +224,21 @@ func (c *Cluster) addOneMember(ctx context.Context) error {
 	return nil
 }
 
+// removeOneMember removes an outdated member if there is one, so that a member added
+// to replace it brings the cluster back to its size. Otherwise it removes any member.
 func (c *Cluster) removeOneMember(ctx context.Context) error {
+	running, _, err := c.pollPods(ctx)
+	if err != nil {
+		return err
+	}
+	outdated, err := c.outdatedMembers(ctx, running)
+	if err != nil {
+		return err
+	}
+	if outdated.Size() > 0 {
+		return c.removeOutdatedMember(ctx, running, outdated.PickOne())
+	}
+
 	c.status.SetScalingDownCondition(c.members.Size(), c.cluster.Spec.Size)
 
 	return c.removeMember(ctx, c.members.PickOne())
@@ -210,12 +300,13 @@ func needUpgrade(pods []*v1.Pod, cs api.ClusterSpec) bool {
 	return len(pods) == cs.Size && pickOneOldMember(pods, cs.Version) != nil
 }
 
-func pickOneOldMember(pods []*v1.Pod, newVersion string) *etcdutil.Member {
+// pickOneOldMember returns the pod of a member that does not run newVersion, or nil if all members do.
+func pickOneOldMember(pods []*v1.Pod, newVersion string) *v1.Pod {
 	for _, pod := range pods {
 		if k8sutil.GetEtcdVersion(pod) == newVersion {
 			continue
 		}
-		return &etcdutil.Member{Name: pod.Name, Namespace: pod.Namespace}
+		return pod
 	}
 	return nil
 }
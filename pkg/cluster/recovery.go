@@ -0,0 +1,381 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/util"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/etcdutil"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
+
+	"github.com/pborman/uuid"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// backupRecoveryTimeout is how long a cluster waits for the restore operator to replace it,
+// before it gives up the recovery from a backup.
+const backupRecoveryTimeout = 10 * time.Minute
+
+// memberRecovery tracks the recovery of a cluster from one of its surviving members.
+type memberRecovery struct {
+	// seed is the name of the member the cluster is recovered from.
+	seed string
+	// from describes the data the cluster is recovered from.
+	from string
+	// forcedReady is set once the seed member became ready with --force-new-cluster.
+	forcedReady bool
+}
+
+// backupRecovery tracks the recovery of a cluster from a backup by the restore operator.
+type backupRecovery struct {
+	// restore is the name of the EtcdRestore the cluster is handed over to.
+	restore string
+	// from describes the snapshot the cluster is recovered from.
+	from string
+	// started is when the EtcdRestore was created.
+	started time.Time
+}
+
+func (c *Cluster) canRecoverFromMembers() bool {
+	return c.cluster.Spec.Recovery != nil && c.cluster.Spec.Recovery.FromMembers && c.isPodPVEnabled()
+}
+
+func (c *Cluster) canRecoverFromBackup() bool {
+	return c.cluster.Spec.Recovery != nil && len(c.cluster.Spec.Recovery.EtcdBackup) != 0
+}
+
+// errNoMemberRevision is returned by recoverFromMembers if no running member can be recovered from.
+var errNoMemberRevision = errors.New("recover from members: failed to get the revision of any running member")
+
+// recoverFromQuorumLoss starts the disaster recovery of a cluster that lost quorum with the given running members.
+// It returns cause if the recovery policy of the cluster does not allow to recover. If the recovery from a backup
+// cannot be started, or failed before, it returns a fatal error that wraps cause.
+func (c *Cluster) recoverFromQuorumLoss(ctx context.Context, running etcdutil.MemberSet, cause error) error {
+	if c.canRecoverFromMembers() && running.Size() > 0 {
+		err := c.recoverFromMembers(ctx, running)
+		if err != errNoMemberRevision || !c.canRecoverFromBackup() {
+			return err
+		}
+	}
+	if !c.canRecoverFromBackup() {
+		return cause
+	}
+	// A backup recovery that failed or timed out would most likely fail again.
+	if len(c.backupRecoveryFailure) != 0 {
+		return wrapFatalError(cause, "recovery from backup failed: "+c.backupRecoveryFailure)
+	}
+	if err := c.recoverFromBackup(ctx); err != nil {
+		return wrapFatalError(cause, err.Error())
+	}
+	return nil
+}
+
+// recoverFromMembers drops all members but the running member with the highest revision,
+// and restarts that member on its PVC as a new one member cluster.
+func (c *Cluster) recoverFromMembers(ctx context.Context, running etcdutil.MemberSet) error {
+	var seed *etcdutil.Member
+	maxRev := int64(-1)
+	for _, m := range running {
+		rev, err := etcdutil.MemberRevision(m.ClientURL(), c.tlsConfig)
+		if err != nil {
+			c.logger.Warningf("failed to get revision of member (%s): %v", m.Name, err)
+			continue
+		}
+		c.logger.Infof("member (%s) is at revision %d", m.Name, rev)
+		if rev > maxRev {
+			seed, maxRev = m, rev
+		}
+	}
+	if seed == nil {
+		return errNoMemberRevision
+	}
+
+	from := fmt.Sprintf("member %s at revision %d", seed.Name, maxRev)
+	c.logger.Infof("recovering cluster from %s", from)
+	c.status.SetRecoveringCondition(from)
+	_, err := c.eventsCli.Create(ctx, k8sutil.RecoveringClusterEvent(from, c.cluster), metav1.CreateOptions{})
+	if err != nil {
+		c.logger.Errorf("failed to create recovering cluster event: %v", err)
+	}
+
+	for _, m := range c.members {
+		if m.Name == seed.Name {
+			continue
+		}
+		if err := c.removePod(ctx, m.Name); err != nil {
+			return err
+		}
+		if err := c.removePVC(ctx, k8sutil.PVCNameFromMember(m.Name)); err != nil {
+			return err
+		}
+		c.logger.Infof("dropped member (%s) from the recovered cluster", m.Name)
+	}
+	// Keep the member ID, etcd keeps it when forcing a new cluster.
+	c.members = etcdutil.NewMemberSet(c.members[seed.Name])
+	c.memberRecovery = &memberRecovery{seed: seed.Name, from: from}
+
+	return c.removePod(ctx, seed.Name)
+}
+
+// reconcileMemberRecovery drives the seed member of a member recovery through
+// 1. a restart with --force-new-cluster until it is ready as a one member cluster,
+// 2. a restart without --force-new-cluster, so that a later restart cannot drop
+// the members added while scaling back up.
+func (c *Cluster) reconcileMemberRecovery(ctx context.Context, pods []*v1.Pod) error {
+	r := c.memberRecovery
+	for _, pod := range pods {
+		if pod.Name != r.seed {
+			continue
+		}
+		if !k8sutil.IsForceNewClusterPod(pod) {
+			c.logger.Infof("cluster recovered from %s", r.from)
+			_, err := c.eventsCli.Create(ctx, k8sutil.ClusterRecoveredEvent(r.from, c.cluster), metav1.CreateOptions{})
+			if err != nil {
+				c.logger.Errorf("failed to create cluster recovered event: %v", err)
+			}
+			c.status.ClearCondition(api.ClusterConditionRecovering)
+			c.memberRecovery = nil
+			return nil
+		}
+		if !k8sutil.IsPodReady(pod) {
+			c.logger.Infof("waiting for seed member (%s) to become ready", r.seed)
+			return nil
+		}
+		r.forcedReady = true
+		c.logger.Infof("restarting seed member (%s) without --force-new-cluster", r.seed)
+		return c.removePod(ctx, r.seed)
+	}
+
+	old, err := c.config.KubeCli.CoreV1().Pods(c.cluster.Namespace).Get(ctx, r.seed, metav1.GetOptions{})
+	if err == nil {
+		if old.DeletionTimestamp == nil {
+			return c.removePod(ctx, r.seed)
+		}
+		c.logger.Infof("waiting for pod of seed member (%s) to be deleted", r.seed)
+		return nil
+	}
+	if !k8sutil.IsKubernetesResourceNotFoundError(err) {
+		return fmt.Errorf("fail to get pod of seed member (%s): %v", r.seed, err)
+	}
+
+	pvc, err := c.config.KubeCli.CoreV1().PersistentVolumeClaims(c.cluster.Namespace).Get(ctx, k8sutil.PVCNameFromMember(r.seed), metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get PVC of seed member (%s): %v", r.seed, err)
+	}
+	m := c.members[r.seed]
+	m.ClusterDomain = c.cluster.Spec.Pod.ClusterDomain
+	pod, err := k8sutil.NewEtcdPod(ctx, c.config.KubeCli, m, c.members.PeerURLPairs(), c.cluster.Name, c.cluster.Namespace, "existing", uuid.New(), c.cluster.Spec, c.cluster.AsOwner())
+	if err != nil {
+		return err
+	}
+	k8sutil.AddEtcdVolumeToPod(pod, pvc, false)
+	if !r.forcedReady {
+		k8sutil.AddForceNewClusterFlag(pod)
+		// Never restart etcd with --force-new-cluster behind the operator's back.
+		pod.Spec.RestartPolicy = v1.RestartPolicyNever
+	}
+	_, err = c.config.KubeCli.CoreV1().Pods(c.cluster.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("fail to create seed member's pod (%s): %v", r.seed, err)
+	}
+	return nil
+}
+
+// recoverFromBackup restores the cluster from the newest snapshot of the EtcdBackup in its recovery policy
+// by handing it over to the restore operator.
+func (c *Cluster) recoverFromBackup(ctx context.Context) error {
+	name := c.cluster.Spec.Recovery.EtcdBackup
+	eb, err := c.config.EtcdCRCli.EtcdV1beta2().EtcdBackups(c.cluster.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("recover from backup: failed to get EtcdBackup (%s): %v", name, err)
+	}
+	rs, path, err := latestRestoreSource(eb)
+	if err != nil {
+		return fmt.Errorf("recover from backup: %v", err)
+	}
+
+	er := &api.EtcdRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			// The restore operator requires the EtcdRestore to be named after the restored cluster.
+			Name:      c.cluster.Name,
+			Namespace: c.cluster.Namespace,
+		},
+		Spec: api.RestoreSpec{
+			BackupStorageType: eb.Spec.StorageType,
+			RestoreSource:     rs,
+			EtcdCluster:       api.EtcdClusterRef{Name: c.cluster.Name},
+			Encryption:        eb.Spec.Encryption,
+		},
+	}
+	restores := c.config.EtcdCRCli.EtcdV1beta2().EtcdRestores(c.cluster.Namespace)
+	_, err = restores.Create(ctx, er, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		old, gerr := restores.Get(ctx, er.Name, metav1.GetOptions{})
+		if gerr != nil {
+			return fmt.Errorf("recover from backup: failed to get EtcdRestore (%s): %v", er.Name, gerr)
+		}
+		if !old.Status.IsFinished() {
+			// E.g. the operator was restarted during the recovery, follow the restore in progress.
+			from := fmt.Sprintf("EtcdRestore %s in progress", old.Name)
+			c.logger.Infof("recovering cluster from %s", from)
+			c.backupRecovery = &backupRecovery{restore: old.Name, from: from, started: time.Now()}
+			return nil
+		}
+		// A finished EtcdRestore is ignored by the restore operator, replace it.
+		if derr := restores.Delete(ctx, er.Name, metav1.DeleteOptions{}); derr != nil && !k8sutil.IsKubernetesResourceNotFoundError(derr) {
+			return fmt.Errorf("recover from backup: failed to delete finished EtcdRestore (%s): %v", er.Name, derr)
+		}
+		_, err = restores.Create(ctx, er, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("recover from backup: failed to create EtcdRestore (%s): %v", er.Name, err)
+	}
+
+	from := fmt.Sprintf("snapshot %s of EtcdBackup %s at revision %d", path, eb.Name, eb.Status.EtcdRevision)
+	c.logger.Infof("recovering cluster from %s", from)
+	c.status.SetRecoveringCondition(from)
+	_, err = c.eventsCli.Create(ctx, k8sutil.RecoveringClusterEvent(from, c.cluster), metav1.CreateOptions{})
+	if err != nil {
+		c.logger.Errorf("failed to create recovering cluster event: %v", err)
+	}
+	c.backupRecovery = &backupRecovery{restore: er.Name, from: from, started: time.Now()}
+	return nil
+}
+
+// reconcileBackupRecovery checks the EtcdRestore the cluster is handed over to. It returns true while the restore
+// is in progress. Once the restore is finished or deleted, or it did not replace the cluster in time,
+// it gives the cluster back to reconciliation and returns false. A failed restore is not started again.
+func (c *Cluster) reconcileBackupRecovery(ctx context.Context) bool {
+	r := c.backupRecovery
+	var failure string
+	er, err := c.config.EtcdCRCli.EtcdV1beta2().EtcdRestores(c.cluster.Namespace).Get(ctx, r.restore, metav1.GetOptions{})
+	switch {
+	case k8sutil.IsKubernetesResourceNotFoundError(err):
+		failure = fmt.Sprintf("EtcdRestore (%s) was deleted", r.restore)
+	case err != nil:
+		c.logger.Warningf("failed to get EtcdRestore (%s): %v", r.restore, err)
+	case er.Status.Succeeded:
+		c.logger.Infof("cluster recovered from %s", r.from)
+		c.status.ClearCondition(api.ClusterConditionRecovering)
+		c.backupRecovery = nil
+		return false
+	case er.Status.IsFinished():
+		failure = fmt.Sprintf("EtcdRestore (%s) failed: %s", r.restore, er.Status.Reason)
+	}
+	if len(failure) == 0 && time.Since(r.started) > backupRecoveryTimeout {
+		failure = fmt.Sprintf("EtcdRestore (%s) did not replace the cluster within %v", r.restore, backupRecoveryTimeout)
+	}
+	if len(failure) == 0 {
+		return true
+	}
+
+	c.logger.Errorf("failed to recover cluster from %s: %s", r.from, failure)
+	c.status.SetRecoveryFailedCondition(failure)
+	if err := c.updateCRStatus(ctx); err != nil {
+		c.logger.Warningf("update CR status failed: %v", err)
+	}
+	c.backupRecovery = nil
+	c.backupRecoveryFailure = failure
+	return false
+}
+
+// latestRestoreSource returns the restore source of the newest snapshot taken by the EtcdBackup, and its path.
+// The path is the one recorded in the history of the EtcdBackup.
+func latestRestoreSource(eb *api.EtcdBackup) (api.RestoreSource, string, error) {
+	if eb.Status.LastSuccessDate.IsZero() {
+		return api.RestoreSource{}, "", fmt.Errorf("EtcdBackup (%s) has no successful snapshot", eb.Name)
+	}
+	pathOf := func(basePath string) (string, error) {
+		for _, a := range eb.Status.History {
+			if !a.Succeeded || len(a.Path) == 0 {
+				continue
+			}
+			if !util.IsBackupPathOf(a.Path, basePath) {
+				return "", fmt.Errorf("last snapshot (%s) of EtcdBackup (%s) is not at %s", a.Path, eb.Name, basePath)
+			}
+			return a.Path, nil
+		}
+		// EtcdBackups of older versions of the backup operator have no history, the path is derived from the status.
+		// The compression codec may have been changed since the snapshot was taken.
+		if eb.Spec.BackupPolicy.IsPeriodic() {
+			return util.PeriodicBackupPath(basePath, eb.Status.EtcdRevision, eb.Status.LastSuccessDate.Time) + eb.Spec.Compression.Extension(), nil
+		}
+		return basePath, nil
+	}
+
+	var rs api.RestoreSource
+	var path string
+	var err error
+	switch eb.Spec.StorageType {
+	case api.BackupStorageTypeS3:
+		if eb.Spec.S3 == nil {
+			break
+		}
+		if path, err = pathOf(eb.Spec.S3.Path); err != nil {
+			return rs, "", err
+		}
+		rs.S3 = &api.S3RestoreSource{
+			Path:           path,
+			AWSSecret:      eb.Spec.S3.AWSSecret,
+			Endpoint:       eb.Spec.S3.Endpoint,
+			ForcePathStyle: eb.Spec.S3.ForcePathStyle,
+		}
+	case api.BackupStorageTypeABS:
+		if eb.Spec.ABS == nil {
+			break
+		}
+		if path, err = pathOf(eb.Spec.ABS.Path); err != nil {
+			return rs, "", err
+		}
+		rs.ABS = &api.ABSRestoreSource{Path: path, ABSSecret: eb.Spec.ABS.ABSSecret}
+	case api.BackupStorageTypeGCS:
+		if eb.Spec.GCS == nil {
+			break
+		}
+		if path, err = pathOf(eb.Spec.GCS.Path); err != nil {
+			return rs, "", err
+		}
+		rs.GCS = &api.GCSRestoreSource{Path: path, GCPSecret: eb.Spec.GCS.GCPSecret}
+	case api.BackupStorageTypeOSS:
+		if eb.Spec.OSS == nil {
+			break
+		}
+		if path, err = pathOf(eb.Spec.OSS.Path); err != nil {
+			return rs, "", err
+		}
+		rs.OSS = &api.OSSRestoreSource{Path: path, OSSSecret: eb.Spec.OSS.OSSSecret, Endpoint: eb.Spec.OSS.Endpoint}
+	case api.BackupStorageTypeLocal:
+		if eb.Spec.Local == nil {
+			break
+		}
+		if path, err = pathOf(eb.Spec.Local.Path); err != nil {
+			return rs, "", err
+		}
+		rs.Local = &api.LocalRestoreSource{Path: path}
+	default:
+		return rs, "", fmt.Errorf("EtcdBackup (%s) has unknown storage type: %v", eb.Name, eb.Spec.StorageType)
+	}
+	if len(path) == 0 {
+		return rs, "", fmt.Errorf("EtcdBackup (%s) has no %s source", eb.Name, eb.Spec.StorageType)
+	}
+	return rs, path, nil
+}
@@ -0,0 +1,253 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/generated/clientset/versioned/fake"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLatestRestoreSource(t *testing.T) {
+	success := metav1.NewTime(time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC))
+	tests := []struct {
+		spec     api.BackupSpec
+		status   api.BackupStatus
+		wantPath string
+		wantErr  bool
+	}{{
+		// one-shot backups are saved at their path
+		spec: api.BackupSpec{
+			StorageType:  api.BackupStorageTypeS3,
+			BackupSource: api.BackupSource{S3: &api.S3BackupSource{Path: "bucket/etcd.backup", AWSSecret: "aws"}},
+		},
+		status:   api.BackupStatus{EtcdRevision: 42, LastSuccessDate: success},
+		wantPath: "bucket/etcd.backup",
+	}, {
+		// periodic backups get the revision and time appended
+		spec: api.BackupSpec{
+			StorageType:  api.BackupStorageTypeGCS,
+			BackupPolicy: &api.BackupPolicy{BackupIntervalInSecond: 60},
+			BackupSource: api.BackupSource{GCS: &api.GCSBackupSource{Path: "bucket/etcd.backup"}},
+		},
+		status:   api.BackupStatus{EtcdRevision: 42, LastSuccessDate: success},
+		wantPath: "bucket/etcd.backup_v42_2026-03-04-05:06:07",
+	}, {
+		// and the extension of the compression codec
+		spec: api.BackupSpec{
+			StorageType:  api.BackupStorageTypeGCS,
+			BackupPolicy: &api.BackupPolicy{BackupIntervalInSecond: 60},
+			BackupSource: api.BackupSource{GCS: &api.GCSBackupSource{Path: "bucket/etcd.backup"}},
+			Compression:  api.BackupCompressionZstd,
+		},
+		status:   api.BackupStatus{EtcdRevision: 42, LastSuccessDate: success},
+		wantPath: "bucket/etcd.backup_v42_2026-03-04-05:06:07.zst",
+	}, {
+		// as do backups with a schedule
+		spec: api.BackupSpec{
+			StorageType:  api.BackupStorageTypeS3,
+			BackupPolicy: &api.BackupPolicy{Schedule: "@daily"},
+			BackupSource: api.BackupSource{S3: &api.S3BackupSource{Path: "bucket/etcd.backup", AWSSecret: "aws"}},
+		},
+		status:   api.BackupStatus{EtcdRevision: 42, LastSuccessDate: success},
+		wantPath: "bucket/etcd.backup_v42_2026-03-04-05:06:07",
+	}, {
+		// the path of the last successful attempt is restored, even if the compression codec was changed since
+		spec: api.BackupSpec{
+			StorageType:  api.BackupStorageTypeS3,
+			BackupPolicy: &api.BackupPolicy{BackupIntervalInSecond: 60},
+			BackupSource: api.BackupSource{S3: &api.S3BackupSource{Path: "bucket/etcd.backup", AWSSecret: "aws"}},
+			Compression:  api.BackupCompressionZstd,
+		},
+		status: api.BackupStatus{EtcdRevision: 42, LastSuccessDate: success, History: []api.BackupAttempt{
+			{Succeeded: false, Reason: "timeout"},
+			{Path: "bucket/etcd.backup_v42_2026-03-04-05:06:07.gz", EtcdRevision: 42, Succeeded: true},
+		}},
+		wantPath: "bucket/etcd.backup_v42_2026-03-04-05:06:07.gz",
+	}, {
+		// the last snapshot was saved at another path
+		spec: api.BackupSpec{
+			StorageType:  api.BackupStorageTypeS3,
+			BackupPolicy: &api.BackupPolicy{BackupIntervalInSecond: 60},
+			BackupSource: api.BackupSource{S3: &api.S3BackupSource{Path: "bucket/etcd.backup", AWSSecret: "aws"}},
+		},
+		status: api.BackupStatus{EtcdRevision: 42, LastSuccessDate: success, History: []api.BackupAttempt{
+			{Path: "bucket/etcd.backup-prod_v42_2026-03-04-05:06:07", EtcdRevision: 42, Succeeded: true},
+		}},
+		wantErr: true,
+	}, {
+		spec: api.BackupSpec{
+			StorageType:  api.BackupStorageTypeLocal,
+			BackupSource: api.BackupSource{Local: &api.LocalBackupSource{Path: "mycluster/etcd.backup"}},
+		},
+		status:   api.BackupStatus{EtcdRevision: 42, LastSuccessDate: success},
+		wantPath: "mycluster/etcd.backup",
+	}, {
+		// no snapshot has been taken yet
+		spec: api.BackupSpec{
+			StorageType:  api.BackupStorageTypeS3,
+			BackupSource: api.BackupSource{S3: &api.S3BackupSource{Path: "bucket/etcd.backup"}},
+		},
+		wantErr: true,
+	}, {
+		// storage type without source
+		spec: api.BackupSpec{
+			StorageType:  api.BackupStorageTypeABS,
+			BackupSource: api.BackupSource{S3: &api.S3BackupSource{Path: "bucket/etcd.backup"}},
+		},
+		status:  api.BackupStatus{EtcdRevision: 42, LastSuccessDate: success},
+		wantErr: true,
+	}}
+
+	for i, tt := range tests {
+		eb := &api.EtcdBackup{ObjectMeta: metav1.ObjectMeta{Name: "backup"}, Spec: tt.spec, Status: tt.status}
+		rs, path, err := latestRestoreSource(eb)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("#%d: expected error, got none", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		if path != tt.wantPath {
+			t.Errorf("#%d: path = %q, want %q", i, path, tt.wantPath)
+		}
+		switch {
+		case rs.S3 != nil && rs.S3.Path == path && rs.S3.AWSSecret == tt.spec.S3.AWSSecret:
+		case rs.GCS != nil && rs.GCS.Path == path:
+		case rs.Local != nil && rs.Local.Path == path:
+		default:
+			t.Errorf("#%d: unexpected restore source: %+v", i, rs)
+		}
+	}
+}
+
+func TestReconcileBackupRecovery(t *testing.T) {
+	newRestore := func(status api.RestoreStatus) *api.EtcdRestore {
+		return &api.EtcdRestore{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+			Status:     status,
+		}
+	}
+	tests := []struct {
+		er         *api.EtcdRestore
+		started    time.Time
+		wRestoring bool
+		// wCondition is the status of the Recovering condition, empty if it is cleared.
+		wCondition v1.ConditionStatus
+	}{
+		{er: newRestore(api.RestoreStatus{Phase: api.RestorePhaseFetching}), started: time.Now(), wRestoring: true, wCondition: v1.ConditionTrue},
+		{er: newRestore(api.RestoreStatus{}), started: time.Now().Add(-backupRecoveryTimeout - time.Minute), wCondition: v1.ConditionFalse},
+		{er: newRestore(api.RestoreStatus{Phase: api.RestorePhaseFailed, Reason: "backup not found"}), started: time.Now(), wCondition: v1.ConditionFalse},
+		{er: newRestore(api.RestoreStatus{Phase: api.RestorePhaseCompleted, Succeeded: true}), started: time.Now()},
+		{started: time.Now(), wCondition: v1.ConditionFalse},
+	}
+	for i, tt := range tests {
+		ec := &api.EtcdCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault}}
+		crcli := fake.NewSimpleClientset(ec)
+		if tt.er != nil {
+			crcli = fake.NewSimpleClientset(ec, tt.er)
+		}
+		c := &Cluster{
+			logger:         logrus.WithField("pkg", "cluster"),
+			config:         Config{EtcdCRCli: crcli},
+			cluster:        ec,
+			backupRecovery: &backupRecovery{restore: "test", from: "snapshot", started: tt.started},
+		}
+		c.status.SetRecoveringCondition("snapshot")
+
+		restoring := c.reconcileBackupRecovery(context.Background())
+		if restoring != tt.wRestoring || (c.backupRecovery != nil) != tt.wRestoring {
+			t.Errorf("#%d: restoring get=%v, want=%v", i, restoring, tt.wRestoring)
+		}
+		var condition v1.ConditionStatus
+		for _, cond := range c.status.Conditions {
+			if cond.Type == api.ClusterConditionRecovering {
+				condition = cond.Status
+			}
+		}
+		if condition != tt.wCondition {
+			t.Errorf("#%d: recovering condition get=%q, want=%q", i, condition, tt.wCondition)
+		}
+		if failed := len(c.backupRecoveryFailure) != 0; failed != (tt.wCondition == v1.ConditionFalse) {
+			t.Errorf("#%d: recovery failure get=%q, want failed=%v", i, c.backupRecoveryFailure, tt.wCondition == v1.ConditionFalse)
+		}
+	}
+}
+
+func TestRecoverFromQuorumLoss(t *testing.T) {
+	eb := &api.EtcdBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: metav1.NamespaceDefault},
+		Spec: api.BackupSpec{
+			StorageType:  api.BackupStorageTypeS3,
+			BackupSource: api.BackupSource{S3: &api.S3BackupSource{Path: "bucket/etcd.backup", AWSSecret: "aws"}},
+		},
+		Status: api.BackupStatus{EtcdRevision: 42, LastSuccessDate: metav1.Now()},
+	}
+	inProgress := &api.EtcdRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+		Status:     api.RestoreStatus{Phase: api.RestorePhaseFetching},
+	}
+	tests := []struct {
+		objs    []runtime.Object
+		failure string
+		// wRecovering tells whether a backup recovery is in progress, wFatal whether the cluster fails instead.
+		wRecovering bool
+		wFatal      bool
+	}{
+		{objs: []runtime.Object{eb}, wRecovering: true},
+		{objs: []runtime.Object{eb, inProgress}, wRecovering: true},
+		// the EtcdBackup is missing
+		{wFatal: true},
+		// a failed backup recovery is not started again
+		{objs: []runtime.Object{eb}, failure: "EtcdRestore (test) was deleted", wFatal: true},
+	}
+	for i, tt := range tests {
+		ec := &api.EtcdCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault}}
+		ec.Spec.Recovery = &api.RecoveryPolicy{EtcdBackup: "backup"}
+		crcli := fake.NewSimpleClientset(append(tt.objs, ec)...)
+		c := &Cluster{
+			logger:                logrus.WithField("pkg", "cluster"),
+			config:                Config{EtcdCRCli: crcli},
+			cluster:               ec,
+			eventsCli:             kubefake.NewSimpleClientset().CoreV1().Events(metav1.NamespaceDefault),
+			backupRecoveryFailure: tt.failure,
+		}
+
+		err := c.recoverFromQuorumLoss(context.Background(), nil, ErrLostQuorum)
+		if isFatalError(err) != tt.wFatal || (err != nil && !errors.Is(err, ErrLostQuorum)) {
+			t.Errorf("#%d: err get=%v, want fatal=%v wrapping %v", i, err, tt.wFatal, ErrLostQuorum)
+		}
+		if (c.backupRecovery != nil) != tt.wRecovering {
+			t.Errorf("#%d: backup recovery get=%+v, want recovering=%v", i, c.backupRecovery, tt.wRecovering)
+		}
+		if _, err := crcli.EtcdV1beta2().EtcdRestores(metav1.NamespaceDefault).Get(context.Background(), "test", metav1.GetOptions{}); (err == nil) != tt.wRecovering {
+			t.Errorf("#%d: EtcdRestore get err=%v, want exists=%v", i, err, tt.wRecovering)
+		}
+	}
+}
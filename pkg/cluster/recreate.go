@@ -0,0 +1,108 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/on2itsecurity/etcd-operator/pkg/util/etcdutil"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
+
+	"github.com/pborman/uuid"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxMemberRecreations is the number of times a dead member is recreated on its PVC
+// without becoming ready before its data is considered corrupt and the member is replaced.
+const maxMemberRecreations = 3
+
+func (c *Cluster) isStableStorageEnabled() bool {
+	return c.isPodPVEnabled() && c.cluster.Spec.Pod.StableStorage
+}
+
+// pickRecreatableMember returns a dead member whose PVC is still usable, together with that PVC.
+// It returns a nil member if none of the dead members can be recreated.
+func (c *Cluster) pickRecreatableMember(ctx context.Context, dead etcdutil.MemberSet) (*etcdutil.Member, *v1.PersistentVolumeClaim, error) {
+	for _, m := range dead {
+		if c.memberRecreations[m.Name] >= maxMemberRecreations {
+			c.logger.Warningf("member (%s) did not become ready after %d recreations, not reusing its PVC", m.Name, maxMemberRecreations)
+			continue
+		}
+		pvc, err := c.config.KubeCli.CoreV1().PersistentVolumeClaims(c.cluster.Namespace).Get(ctx, k8sutil.PVCNameFromMember(m.Name), metav1.GetOptions{})
+		if err != nil {
+			if k8sutil.IsKubernetesResourceNotFoundError(err) {
+				c.logger.Warningf("PVC of member (%s) is gone", m.Name)
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to get PVC of member (%s): %v", m.Name, err)
+		}
+		if pvc.DeletionTimestamp != nil || pvc.Status.Phase == v1.ClaimLost {
+			c.logger.Warningf("PVC of member (%s) is lost or being deleted", m.Name)
+			continue
+		}
+		return m, pvc, nil
+	}
+	return nil, nil, nil
+}
+
+// recreateDeadMember recreates the pod of a dead member with the same name on its existing PVC,
+// so that the member rejoins the cluster with its data and member ID.
+// A member pod is only created once the previous pod is completely gone from the API server,
+// so that no two pods ever use the same PVC at the same time.
+func (c *Cluster) recreateDeadMember(ctx context.Context, m *etcdutil.Member, pvc *v1.PersistentVolumeClaim) error {
+	old, err := c.config.KubeCli.CoreV1().Pods(c.cluster.Namespace).Get(ctx, m.Name, metav1.GetOptions{})
+	if err == nil {
+		if old.DeletionTimestamp == nil {
+			c.logger.Infof("deleting pod of dead member (%s) before recreating it", m.Name)
+			return c.removePod(ctx, m.Name)
+		}
+		c.logger.Infof("waiting for pod of dead member (%s) to be deleted", m.Name)
+		return nil
+	}
+	if !k8sutil.IsKubernetesResourceNotFoundError(err) {
+		return fmt.Errorf("fail to get pod of dead member (%s): %v", m.Name, err)
+	}
+
+	c.logger.Infof("recreating dead member %q on PVC %q", m.Name, pvc.Name)
+	_, err = c.eventsCli.Create(ctx, k8sutil.RecreatingDeadMemberEvent(m.Name, c.cluster), metav1.CreateOptions{})
+	if err != nil {
+		c.logger.Errorf("failed to create recreating dead member event: %v", err)
+	}
+
+	m.ClusterDomain = c.cluster.Spec.Pod.ClusterDomain
+	// The data dir on the PVC already holds the membership, so the initial cluster flags are ignored by etcd.
+	pod, err := k8sutil.NewEtcdPod(ctx, c.config.KubeCli, m, c.members.PeerURLPairs(), c.cluster.Name, c.cluster.Namespace, "existing", uuid.New(), c.cluster.Spec, c.cluster.AsOwner())
+	if err != nil {
+		return err
+	}
+	k8sutil.AddEtcdVolumeToPod(pod, pvc, false)
+	_, err = c.config.KubeCli.CoreV1().Pods(c.cluster.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("fail to recreate member's pod (%s): %v", m.Name, err)
+	}
+	c.memberRecreations[m.Name]++
+	return nil
+}
+
+// resetMemberRecreations forgets the recreation attempts of members that became ready.
+func (c *Cluster) resetMemberRecreations(pods []*v1.Pod) {
+	for _, pod := range pods {
+		if k8sutil.IsPodReady(pod) {
+			delete(c.memberRecreations, pod.Name)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/etcdutil"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPickRecreatableMember(t *testing.T) {
+	m := &etcdutil.Member{Name: "test-abc", Namespace: metav1.NamespaceDefault}
+	boundPVC := &v1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: m.Name, Namespace: metav1.NamespaceDefault},
+		Status:     v1.PersistentVolumeClaimStatus{Phase: v1.ClaimBound},
+	}
+	lostPVC := boundPVC.DeepCopy()
+	lostPVC.Status.Phase = v1.ClaimLost
+
+	tests := []struct {
+		pvc          *v1.PersistentVolumeClaim
+		recreations  int
+		wRecreatable bool
+	}{{
+		pvc:          boundPVC,
+		wRecreatable: true,
+	}, { // PVC is gone
+		pvc:          nil,
+		wRecreatable: false,
+	}, { // PVC is lost
+		pvc:          lostPVC,
+		wRecreatable: false,
+	}, { // member never became ready on its PVC
+		pvc:          boundPVC,
+		recreations:  maxMemberRecreations,
+		wRecreatable: false,
+	}}
+
+	for i, tt := range tests {
+		kubecli := fake.NewSimpleClientset()
+		if tt.pvc != nil {
+			kubecli = fake.NewSimpleClientset(tt.pvc)
+		}
+		c := &Cluster{
+			logger: logrus.WithField("pkg", "cluster"),
+			config: Config{KubeCli: kubecli},
+			cluster: &api.EtcdCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+			},
+			memberRecreations: map[string]int{m.Name: tt.recreations},
+		}
+		get, _, err := c.pickRecreatableMember(context.Background(), etcdutil.NewMemberSet(m))
+		if err != nil {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+		if (get != nil) != tt.wRecreatable {
+			t.Errorf("#%d: recreatable get=%v, want=%v", i, get != nil, tt.wRecreatable)
+		}
+	}
+}
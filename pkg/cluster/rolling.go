@@ -0,0 +1,119 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/on2itsecurity/etcd-operator/pkg/util/etcdutil"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// outdatedMembers returns the members whose pods do not match the pod template of the cluster spec.
+// If the TLS certificates are managed, members whose pods use certificates that have since been renewed
+// are outdated as well, except in single member clusters: etcd reloads the renewed certificates from the mounted secrets.
+// Pods created before the pod template was recorded on them are adopted: the current pod template is recorded on them,
+// so they are not replaced now, but are replaced by later changes to the pod template.
+func (c *Cluster) outdatedMembers(ctx context.Context, pods []*v1.Pod) (etcdutil.MemberSet, error) {
+	var certsHash string
+	if c.cluster.Spec.TLS.IsManaged() && c.cluster.Spec.Size > 1 {
+		var err error
+		certsHash, err = k8sutil.TLSCertsHash(ctx, c.config.KubeCli, c.cluster.Namespace, c.cluster.Name, c.cluster.Spec.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get TLS certificates hash: %v", err)
+		}
+	}
+	templateHash := k8sutil.PodTemplateHash(c.cluster.Spec)
+
+	outdated := etcdutil.MemberSet{}
+	for _, pod := range pods {
+		m, ok := c.members[pod.Name]
+		if !ok {
+			continue
+		}
+		h := k8sutil.GetPodTemplateHash(pod)
+		if len(h) == 0 {
+			if err := c.adoptPod(ctx, pod, templateHash); err != nil {
+				return nil, err
+			}
+			h = templateHash
+		}
+		if h != templateHash {
+			outdated.Add(m)
+			continue
+		}
+		if len(certsHash) != 0 && k8sutil.GetTLSCertsHash(pod) != certsHash {
+			outdated.Add(m)
+		}
+	}
+	return outdated, nil
+}
+
+// adoptPod records the pod template hash on a pod that was created before the pod template was recorded on pods.
+func (c *Cluster) adoptPod(ctx context.Context, pod *v1.Pod, templateHash string) error {
+	newPod := pod.DeepCopy()
+	k8sutil.SetPodTemplateHash(newPod, templateHash)
+	patchdata, err := k8sutil.CreatePatch(pod, newPod, v1.Pod{})
+	if err != nil {
+		return fmt.Errorf("error creating patch: %v", err)
+	}
+	_, err = c.config.KubeCli.CoreV1().Pods(pod.Namespace).Patch(ctx, pod.Name, types.StrategicMergePatchType, patchdata, metav1.PatchOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to record the pod template on pod (%s): %v", pod.Name, err)
+	}
+	c.logger.Infof("recorded the pod template on pod (%s)", pod.Name)
+	return nil
+}
+
+// rollOneMember adds a new member to replace one of the outdated members.
+// The cluster is then one member over its size, and the next reconciliation removes an outdated member.
+func (c *Cluster) rollOneMember(ctx context.Context, pods []*v1.Pod, outdated etcdutil.MemberSet) error {
+	if !allPodsReady(pods) {
+		c.logger.Infof("waiting for all members to be ready before replacing outdated members: %s", outdated)
+		return nil
+	}
+	c.logger.Infof("adding a member to replace outdated members: %s", outdated)
+	return c.addOneMember(ctx)
+}
+
+// removeOutdatedMember removes an outdated member once all members are ready,
+// and only if enough ready members are left to satisfy the pod disruption budget.
+func (c *Cluster) removeOutdatedMember(ctx context.Context, pods []*v1.Pod, m *etcdutil.Member) error {
+	if !allPodsReady(pods) || len(pods)-1 < c.calculateMinAvailable() {
+		c.logger.Infof("waiting for all members to be ready before removing outdated member (%s)", m.Name)
+		return nil
+	}
+
+	c.logger.Infof("replacing outdated member (%s)", m.Name)
+	_, err := c.eventsCli.Create(ctx, k8sutil.ReplacingOutdatedMemberEvent(m.Name, c.cluster), metav1.CreateOptions{})
+	if err != nil {
+		c.logger.Errorf("failed to create replacing outdated member event: %v", err)
+	}
+	return c.removeMember(ctx, m)
+}
+
+func allPodsReady(pods []*v1.Pod) bool {
+	for _, pod := range pods {
+		if !k8sutil.IsPodReady(pod) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/etcdutil"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestOutdatedMembers(t *testing.T) {
+	oldSpec := api.ClusterSpec{Size: 3, Repository: "quay.io/coreos/etcd"}
+	newSpec := oldSpec
+	newSpec.Pod = &api.PodPolicy{Labels: map[string]string{"app": "etcd"}}
+
+	newPod := func(name string, cs api.ClusterSpec) *v1.Pod {
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{}}}
+		pod.Annotations["etcd.pod-template"] = k8sutil.PodTemplateHash(cs)
+		return pod
+	}
+	unannotated := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-c", Namespace: metav1.NamespaceDefault}}
+
+	tests := []struct {
+		pods      []*v1.Pod
+		wOutdated []string
+	}{{
+		pods: []*v1.Pod{newPod("test-a", newSpec), newPod("test-b", newSpec)},
+	}, {
+		pods:      []*v1.Pod{newPod("test-a", oldSpec), newPod("test-b", newSpec)},
+		wOutdated: []string{"test-a"},
+	}, {
+		pods:      []*v1.Pod{newPod("test-a", oldSpec), newPod("test-b", oldSpec)},
+		wOutdated: []string{"test-a", "test-b"},
+	}, { // pods created before the pod template was recorded
+		pods: []*v1.Pod{unannotated},
+	}, {
+		pods:      []*v1.Pod{newPod("test-a", oldSpec), unannotated},
+		wOutdated: []string{"test-a"},
+	}}
+
+	for i, tt := range tests {
+		members := etcdutil.MemberSet{}
+		for _, pod := range tt.pods {
+			members.Add(&etcdutil.Member{Name: pod.Name})
+		}
+		kubecli := fake.NewSimpleClientset(unannotated.DeepCopy())
+		c := &Cluster{
+			logger: logrus.WithField("pkg", "cluster"),
+			config: Config{KubeCli: kubecli},
+			cluster: &api.EtcdCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+				Spec:       newSpec,
+			},
+			members: members,
+		}
+		outdated, err := c.outdatedMembers(context.Background(), tt.pods)
+		if err != nil {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+		if outdated.Size() != len(tt.wOutdated) {
+			t.Errorf("#%d: outdated members get=%s, want=%v", i, outdated, tt.wOutdated)
+			continue
+		}
+		for _, name := range tt.wOutdated {
+			if _, ok := outdated[name]; !ok {
+				t.Errorf("#%d: member %s is not outdated, want outdated", i, name)
+			}
+		}
+	}
+}
+
+func TestOutdatedMembersAdoptsUnannotatedPods(t *testing.T) {
+	oldSpec := api.ClusterSpec{Size: 1, Repository: "quay.io/coreos/etcd"}
+	newSpec := oldSpec
+	newSpec.Pod = &api.PodPolicy{Labels: map[string]string{"app": "etcd"}}
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "test-a", Namespace: metav1.NamespaceDefault}}
+	kubecli := fake.NewSimpleClientset(pod)
+	c := &Cluster{
+		logger: logrus.WithField("pkg", "cluster"),
+		config: Config{KubeCli: kubecli},
+		cluster: &api.EtcdCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+			Spec:       oldSpec,
+		},
+		members: etcdutil.NewMemberSet(&etcdutil.Member{Name: pod.Name}),
+	}
+	ctx := context.Background()
+
+	outdated, err := c.outdatedMembers(ctx, []*v1.Pod{pod})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if outdated.Size() != 0 {
+		t.Errorf("outdated members get=%s, want none", outdated)
+	}
+	pod, err = kubecli.CoreV1().Pods(metav1.NamespaceDefault).Get(ctx, pod.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get pod: %v", err)
+	}
+	if h := k8sutil.GetPodTemplateHash(pod); h != k8sutil.PodTemplateHash(oldSpec) {
+		t.Errorf("pod template hash get=%q, want the hash of the current spec", h)
+	}
+
+	c.cluster.Spec = newSpec
+	outdated, err = c.outdatedMembers(ctx, []*v1.Pod{pod})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := outdated[pod.Name]; !ok || outdated.Size() != 1 {
+		t.Errorf("outdated members get=%s, want=[%s]", outdated, pod.Name)
+	}
+}
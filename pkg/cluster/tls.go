@@ -0,0 +1,246 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"time"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/etcdutil"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/tlsutil"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// caValidity is how long the CA certificate generated by the operator is valid.
+// The CA is not rotated, its certificate is only renewed with the same key when it is about to expire.
+var caValidity = 10 * 365 * 24 * time.Hour
+
+// reconcileOperatorTLS makes sure the CA and the certificates of the cluster exist,
+// renews the certificates that are about to expire and reports their expiry in the status.
+// It returns whether any certificate has been (re)issued.
+func (c *Cluster) reconcileOperatorTLS(ctx context.Context) (bool, error) {
+	ot := c.cluster.Spec.TLS.Operator
+	now := time.Now()
+
+	caCert, caKey, caRenewed, err := c.ensureCA(ctx, now, ot.RenewBefore())
+	if err != nil {
+		return false, err
+	}
+
+	issued := caRenewed
+	expiry := caCert.NotAfter
+	for _, cc := range c.clusterCerts() {
+		cert, renewed, err := c.ensureCert(ctx, cc, caCert, caKey, caRenewed, now, ot)
+		if err != nil {
+			return false, err
+		}
+		issued = issued || renewed
+		if cert.NotAfter.Before(expiry) {
+			expiry = cert.NotAfter
+		}
+	}
+
+	c.status.TLS = &api.TLSStatus{
+		CAExpirationDate: metav1.NewTime(caCert.NotAfter),
+		ExpirationDate:   metav1.NewTime(expiry),
+		RenewalDate:      metav1.NewTime(expiry.Add(-ot.RenewBefore())),
+	}
+	return issued, nil
+}
+
+type clusterCert struct {
+	secret string
+	cfg    tlsutil.CertConfig
+}
+
+// clusterCerts returns the certificates of the cluster when the operator or cert-manager issues them.
+// The peer and server certificates use wildcard SANs, so that they cover the Addr() of every member.
+func (c *Cluster) clusterCerts() []clusterCert {
+	name, ns := c.cluster.Name, c.cluster.Namespace
+	var domain string
+	if c.cluster.Spec.Pod != nil {
+		domain = c.cluster.Spec.Pod.ClusterDomain
+	}
+	memberDNSNames := []string{fmt.Sprintf("*.%s.%s.svc", name, ns)}
+	if len(domain) != 0 {
+		memberDNSNames = append(memberDNSNames, fmt.Sprintf("*.%s.%s.svc%s", name, ns, domain))
+	}
+
+	svc := k8sutil.ClientServiceName(name, c.cluster.Spec.Service)
+	serverDNSNames := append([]string{
+		svc,
+		fmt.Sprintf("%s.%s", svc, ns),
+		fmt.Sprintf("%s.%s.svc", svc, ns),
+		"localhost",
+	}, memberDNSNames...)
+	if len(domain) != 0 {
+		serverDNSNames = append(serverDNSNames, fmt.Sprintf("%s.%s.svc%s", svc, ns, domain))
+	}
+
+	tp := c.cluster.Spec.TLS
+	// cert-manager certificates use the default duration of their issuer.
+	var validity time.Duration
+	if tp.Operator != nil {
+		validity = tp.Operator.CertificateValidity()
+	}
+	both := []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+	return []clusterCert{{
+		secret: tp.PeerSecret(name),
+		cfg:    tlsutil.CertConfig{CommonName: name + "-peer", DNSNames: memberDNSNames, ExtKeyUsage: both, Validity: validity},
+	}, {
+		secret: tp.ServerSecret(name),
+		cfg: tlsutil.CertConfig{
+			CommonName:  name + "-server",
+			DNSNames:    serverDNSNames,
+			IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+			ExtKeyUsage: both,
+			Validity:    validity,
+		},
+	}, {
+		secret: tp.OperatorSecret(name),
+		cfg:    tlsutil.CertConfig{CommonName: "etcd-operator", ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}, Validity: validity},
+	}}
+}
+
+// ensureCA returns the CA of the cluster, generating it if it does not exist yet
+// and renewing its certificate with the same key when it is about to expire.
+func (c *Cluster) ensureCA(ctx context.Context, now time.Time, renewBefore time.Duration) (*x509.Certificate, crypto.Signer, bool, error) {
+	name := c.cluster.Spec.TLS.CASecret(c.cluster.Name)
+	secret, err := c.config.KubeCli.CoreV1().Secrets(c.cluster.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil && !k8sutil.IsKubernetesResourceNotFoundError(err) {
+		return nil, nil, false, fmt.Errorf("failed to get CA secret (%s): %v", name, err)
+	}
+	if err == nil {
+		cert, cerr := tlsutil.ParseCertPEM(secret.Data[v1.TLSCertKey])
+		key, kerr := tlsutil.ParsePrivateKeyPEM(secret.Data[v1.TLSPrivateKeyKey])
+		if cerr != nil || kerr != nil {
+			return nil, nil, false, fmt.Errorf("invalid CA secret (%s): %v, %v", name, cerr, kerr)
+		}
+		if now.Before(cert.NotAfter.Add(-renewBefore)) {
+			return cert, key, false, nil
+		}
+		c.logger.Infof("renewing CA certificate expiring at %v", cert.NotAfter)
+		cert, err = tlsutil.NewSelfSignedCACert(key, c.cluster.Name+"-ca", caValidity)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		certPEM := tlsutil.EncodeCertPEM(cert)
+		secret.Data[v1.TLSCertKey] = certPEM
+		secret.Data["ca.crt"] = certPEM
+		if _, err := c.config.KubeCli.CoreV1().Secrets(c.cluster.Namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+			return nil, nil, false, fmt.Errorf("failed to update CA secret (%s): %v", name, err)
+		}
+		return cert, key, true, nil
+	}
+
+	c.logger.Infof("generating CA for the cluster")
+	key, err := tlsutil.NewPrivateKey()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	cert, err := tlsutil.NewSelfSignedCACert(key, c.cluster.Name+"-ca", caValidity)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	keyPEM, err := tlsutil.EncodePrivateKeyPEM(key)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	certPEM := tlsutil.EncodeCertPEM(cert)
+	secret = k8sutil.NewTLSSecret(name, c.cluster.Name, c.cluster.Namespace, certPEM, keyPEM, certPEM, c.cluster.AsOwner())
+	if _, err := c.config.KubeCli.CoreV1().Secrets(c.cluster.Namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		return nil, nil, false, fmt.Errorf("failed to create CA secret (%s): %v", name, err)
+	}
+	return cert, key, true, nil
+}
+
+// ensureCert returns the certificate stored in the secret of cc,
+// issuing a new one if it does not exist, is about to expire or the CA has been renewed.
+func (c *Cluster) ensureCert(ctx context.Context, cc clusterCert, caCert *x509.Certificate, caKey crypto.Signer, force bool, now time.Time, ot *api.OperatorTLS) (*x509.Certificate, bool, error) {
+	secrets := c.config.KubeCli.CoreV1().Secrets(c.cluster.Namespace)
+	secret, err := secrets.Get(ctx, cc.secret, metav1.GetOptions{})
+	if err != nil && !k8sutil.IsKubernetesResourceNotFoundError(err) {
+		return nil, false, fmt.Errorf("failed to get TLS secret (%s): %v", cc.secret, err)
+	}
+	exists := err == nil
+	if exists && !force {
+		cert, err := tlsutil.ParseCertPEM(secret.Data[v1.TLSCertKey])
+		if err == nil && now.Before(cert.NotAfter.Add(-ot.RenewBefore())) {
+			return cert, false, nil
+		}
+		if err != nil {
+			c.logger.Warningf("reissuing invalid certificate in secret (%s): %v", cc.secret, err)
+		} else {
+			c.logger.Infof("renewing certificate in secret (%s) expiring at %v", cc.secret, cert.NotAfter)
+		}
+	}
+
+	key, err := tlsutil.NewPrivateKey()
+	if err != nil {
+		return nil, false, err
+	}
+	cert, err := tlsutil.NewSignedCert(cc.cfg, key, caCert, caKey)
+	if err != nil {
+		return nil, false, err
+	}
+	keyPEM, err := tlsutil.EncodePrivateKeyPEM(key)
+	if err != nil {
+		return nil, false, err
+	}
+	newSecret := k8sutil.NewTLSSecret(cc.secret, c.cluster.Name, c.cluster.Namespace, tlsutil.EncodeCertPEM(cert), keyPEM, tlsutil.EncodeCertPEM(caCert), c.cluster.AsOwner())
+	if exists {
+		secret.Data = newSecret.Data
+		_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	} else {
+		_, err = secrets.Create(ctx, newSecret, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to save TLS secret (%s): %v", cc.secret, err)
+	}
+	return cert, true, nil
+}
+
+// loadOperatorTLSConfig loads the client TLS config the operator talks to the cluster with,
+// unless the operator secret did not change since it was loaded last.
+func (c *Cluster) loadOperatorTLSConfig(ctx context.Context) error {
+	d, err := k8sutil.GetTLSDataFromSecret(ctx, c.config.KubeCli, c.cluster.Namespace, c.cluster.Spec.TLS.OperatorSecret(c.cluster.Name))
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	h.Write(d.CertData)
+	h.Write(d.KeyData)
+	h.Write(d.CAData)
+	sum := hex.EncodeToString(h.Sum(nil))
+	if c.tlsConfig != nil && sum == c.operatorTLSHash {
+		return nil
+	}
+	c.tlsConfig, err = etcdutil.NewTLSConfig(d.CertData, d.KeyData, d.CAData)
+	if err != nil {
+		return err
+	}
+	c.operatorTLSHash = sum
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestReconcileOperatorTLS(t *testing.T) {
+	ctx := context.Background()
+	kubecli := fake.NewSimpleClientset()
+	c := &Cluster{
+		logger: logrus.WithField("pkg", "cluster"),
+		config: Config{KubeCli: kubecli},
+		cluster: &api.EtcdCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+			Spec: api.ClusterSpec{
+				TLS: &api.TLSPolicy{Operator: &api.OperatorTLS{CertificateValidityInDays: 90, RenewBeforeInDays: 10}},
+			},
+		},
+	}
+
+	issued, err := c.reconcileOperatorTLS(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !issued {
+		t.Error("expected certificates to be issued for a new cluster")
+	}
+	tp := c.cluster.Spec.TLS
+	for _, name := range []string{tp.CASecret("test"), tp.PeerSecret("test"), tp.ServerSecret("test"), tp.OperatorSecret("test")} {
+		if _, err := kubecli.CoreV1().Secrets(metav1.NamespaceDefault).Get(ctx, name, metav1.GetOptions{}); err != nil {
+			t.Errorf("expected secret %s: %v", name, err)
+		}
+	}
+	if c.status.TLS == nil || !c.status.TLS.RenewalDate.Before(&c.status.TLS.ExpirationDate) {
+		t.Fatalf("unexpected TLS status: %+v", c.status.TLS)
+	}
+
+	issued, err = c.reconcileOperatorTLS(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issued {
+		t.Error("expected valid certificates not to be reissued")
+	}
+
+	// renew everything that expires within the validity
+	tp.Operator.RenewBeforeInDays = 90
+	issued, err = c.reconcileOperatorTLS(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !issued {
+		t.Error("expected expiring certificates to be renewed")
+	}
+}
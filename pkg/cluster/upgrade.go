@@ -25,9 +25,8 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 )
 
+// upgradeOneMember replaces the image of the member with the image of the version of the spec.
 func (c *Cluster) upgradeOneMember(ctx context.Context, memberName string) error {
-	c.status.SetUpgradingCondition(c.cluster.Spec.Version)
-
 	ns := c.cluster.Namespace
 
 	pod, err := c.config.KubeCli.CoreV1().Pods(ns).Get(ctx, memberName, metav1.GetOptions{})
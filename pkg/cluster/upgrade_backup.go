@@ -0,0 +1,76 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/util"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// needBackupBeforeUpgrade tells whether the upgrade policy asks for a snapshot before the upgrade to version,
+// and it is not taken yet.
+func (c *Cluster) needBackupBeforeUpgrade(version string) bool {
+	up := c.cluster.Spec.UpgradePolicy
+	if up == nil || !up.BackupBeforeUpgrade {
+		return false
+	}
+	ub := c.status.UpgradeBackup
+	return ub == nil || ub.TargetVersion != version
+}
+
+// backupBeforeUpgrade saves a snapshot of the cluster before it is upgraded to version,
+// with the storage of the backup policy of the cluster, and records it in the status.
+func (c *Cluster) backupBeforeUpgrade(ctx context.Context, version string) error {
+	bp := c.cluster.Spec.Backup
+	if bp == nil {
+		return errors.New("backup before upgrade: spec.backup is not set")
+	}
+	if bp.StorageType == api.BackupStorageTypeLocal {
+		// The local storage is only mounted in the backup operator.
+		return errors.New("backup before upgrade: local backup storage is not supported")
+	}
+	bw, basePath, closeWriter, err := backup.NewWriter(ctx, c.config.KubeCli, c.cluster.Namespace, bp.StorageType, &bp.BackupSource, bp.Encryption)
+	if err != nil {
+		return fmt.Errorf("backup before upgrade: %v", err)
+	}
+	defer closeWriter()
+
+	path := util.UpgradeBackupPath(basePath, version, time.Now()) + bp.Compression.Extension()
+	bm := backup.NewBackupManagerFromWriter(c.config.KubeCli, bw, c.tlsConfig, c.members.ClientURLs(), c.cluster.Namespace,
+		c.cluster.Name, string(c.cluster.UID), bp.Compression)
+	c.logger.Infof("saving snapshot %s before upgrading to %s", path, version)
+	path, m, err := bm.SaveSnap(ctx, path, false)
+	if err != nil {
+		return fmt.Errorf("backup before upgrade: %v", err)
+	}
+	c.status.UpgradeBackup = &api.UpgradeBackup{
+		FromVersion:   c.status.CurrentVersion,
+		TargetVersion: version,
+		Path:          path,
+		Time:          metav1.NewTime(m.Timestamp),
+	}
+	if err := c.updateCRStatus(ctx); err != nil {
+		c.logger.Warningf("failed to record snapshot before upgrade in CR status: %v", err)
+	}
+	return nil
+}
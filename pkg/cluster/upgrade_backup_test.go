@@ -0,0 +1,82 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestNeedBackupBeforeUpgrade(t *testing.T) {
+	tests := []struct {
+		policy *api.UpgradePolicy
+		backup *api.UpgradeBackup
+		want   bool
+	}{
+		{nil, nil, false},
+		{&api.UpgradePolicy{}, nil, false},
+		{&api.UpgradePolicy{BackupBeforeUpgrade: true}, nil, true},
+		// the snapshot of an earlier upgrade does not count
+		{&api.UpgradePolicy{BackupBeforeUpgrade: true}, &api.UpgradeBackup{TargetVersion: "v3.5.21"}, true},
+		{&api.UpgradePolicy{BackupBeforeUpgrade: true}, &api.UpgradeBackup{TargetVersion: "v3.6.10"}, false},
+	}
+	for i, tt := range tests {
+		c := &Cluster{
+			cluster: &api.EtcdCluster{Spec: api.ClusterSpec{UpgradePolicy: tt.policy}},
+			status:  api.ClusterStatus{UpgradeBackup: tt.backup},
+		}
+		if got := c.needBackupBeforeUpgrade("v3.6.10"); got != tt.want {
+			t.Errorf("#%d: get=%v, want=%v", i, got, tt.want)
+		}
+	}
+}
+
+func TestBackupBeforeUpgradeUnsupportedStorage(t *testing.T) {
+	c := &Cluster{
+		logger: logrus.WithField("pkg", "cluster"),
+		config: Config{KubeCli: fake.NewSimpleClientset()},
+		cluster: &api.EtcdCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+			Spec: api.ClusterSpec{
+				Backup: &api.ClusterBackupPolicy{
+					StorageType:  api.BackupStorageTypeLocal,
+					BackupSource: api.BackupSource{Local: &api.LocalBackupSource{Path: "etcd.backup"}},
+				},
+				UpgradePolicy: &api.UpgradePolicy{BackupBeforeUpgrade: true},
+			},
+		},
+	}
+	if err := c.backupBeforeUpgrade(context.Background(), "v3.6.10"); err == nil {
+		t.Fatal("expected an error for local backup storage")
+	}
+	if c.status.UpgradeBackup != nil {
+		t.Errorf("expected no snapshot to be recorded, got %+v", c.status.UpgradeBackup)
+	}
+}
+
+func TestUpgradingConditionRecordsBackup(t *testing.T) {
+	cs := &api.ClusterStatus{UpgradeBackup: &api.UpgradeBackup{TargetVersion: "v3.6.10", Path: "bucket/pre-upgrade/etcd.backup"}}
+	cs.SetUpgradingCondition("v3.6.10")
+	if len(cs.Conditions) != 1 || !strings.Contains(cs.Conditions[0].Message, "bucket/pre-upgrade/etcd.backup") {
+		t.Errorf("expected the snapshot in the Upgrading condition: %+v", cs.Conditions)
+	}
+}
@@ -94,7 +94,7 @@ func (b *Backup) Start(ctx context.Context) error {
 }
 
 func (b *Backup) initCRD(ctx context.Context) error {
-	err := k8sutil.CreateCRD(ctx, b.kubeExtCli, api.EtcdBackupCRDName, api.EtcdBackupResourceKind, api.EtcdBackupResourcePlural, "")
+	err := k8sutil.CreateCRD(ctx, b.kubeExtCli, api.EtcdBackupCRDName)
 	if err != nil {
 		return fmt.Errorf("failed to create CRD: %v", err)
 	}
@@ -16,16 +16,17 @@ package controller
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"reflect"
 	"time"
 
 	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup"
 	"github.com/on2itsecurity/etcd-operator/pkg/backup/metrics"
 	"github.com/on2itsecurity/etcd-operator/pkg/util/constants"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/cronutil"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/sirupsen/logrus"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -96,6 +97,20 @@ func (b *Backup) processItem(ctx context.Context, key string) error {
 			return err
 		}
 
+		if len(eb.Spec.BackupPolicy.Schedule) != 0 {
+			schedule, err := cronutil.Parse(eb.Spec.BackupPolicy.Schedule, eb.Spec.BackupPolicy.TimeZone)
+			if err != nil {
+				// Report the invalid schedule once, until the spec is changed.
+				b.backupRunnerStore.Store(eb.ObjectMeta.UID, BackupRunner{eb.Spec, func() {}})
+				b.reportBackupStatus(ctx, nil, err, eb.DeepCopy(), time.Now())
+				return nil
+			}
+			ctx, cancel := context.WithCancel(context.Background())
+			go b.scheduledRunnerFunc(ctx, schedule, eb)
+			b.backupRunnerStore.Store(eb.ObjectMeta.UID, BackupRunner{eb.Spec, cancel})
+			return nil
+		}
+
 		var ticker *time.Ticker
 		var duration int64
 		b.logger.Debugf("EtcdBackup name: %s", eb.Name)
@@ -144,9 +159,10 @@ func (b *Backup) processItem(ctx context.Context, key string) error {
 		})).Inc()
 
 		// Perform backup
-		bs, err := b.handleBackup(nil, &eb.Spec, false, eb.Namespace)
+		start := time.Now()
+		bs, err := b.handleBackup(nil, eb, false)
 		// Report backup status
-		b.reportBackupStatus(ctx, bs, err, eb)
+		b.reportBackupStatus(ctx, bs, err, eb, start)
 	}
 	return err
 }
@@ -178,11 +194,11 @@ func (b *Backup) addFinalizerOfPeriodicBackupIfNeed(ctx context.Context, eb *api
 	}
 	if !containsString(metadata.GetFinalizers(), "backup-operator-periodic") {
 		metadata.SetFinalizers(append(metadata.GetFinalizers(), "backup-operator-periodic"))
-		_, err := b.backupCRCli.EtcdV1beta2().EtcdBackups(eb.ObjectMeta.Namespace).Update(ctx, ebNew.(*api.EtcdBackup), metav1.UpdateOptions{})
+		ebUpdated, err := b.backupCRCli.EtcdV1beta2().EtcdBackups(eb.ObjectMeta.Namespace).Update(ctx, ebNew.(*api.EtcdBackup), metav1.UpdateOptions{})
 		if err != nil {
 			return eb, err
 		}
-		return ebNew.(*api.EtcdBackup), nil
+		return ebUpdated, nil
 	}
 	return eb, nil
 }
@@ -214,25 +230,9 @@ func (b *Backup) periodicRunnerFunc(ctx context.Context, t *time.Ticker, eb *api
 			b.logger.Debugln("--------------------- received context kill signal  ---------------------")
 			return
 		case <-t.C:
-			var latestEb *api.EtcdBackup
 			var bs *api.BackupStatus
-			var err error
-			retryLimit := 5
-			for i := 1; i < retryLimit+1; i++ {
-				latestEb, err = b.backupCRCli.EtcdV1beta2().EtcdBackups(eb.Namespace).Get(ctx, eb.Name, metav1.GetOptions{})
-				if err != nil {
-					if apierrors.IsNotFound(err) {
-						b.logger.Infof("Could not find EtcdBackup. Stopping periodic backup for EtcdBackup CR %v",
-							eb.Name)
-						break
-					}
-					b.logger.Warningf("[Attempt: %d/%d] Failed to get latest EtcdBackup %v : (%v)",
-						i, retryLimit, eb.Name, err)
-					time.Sleep(1 * time.Second)
-					continue
-				}
-				break
-			}
+			start := time.Now()
+			latestEb, err := b.getLatestBackup(ctx, eb)
 			if err == nil {
 				metrics.BackupsAttemptedTotal.With(prometheus.Labels{
 					"namespace": eb.ObjectMeta.Namespace,
@@ -240,11 +240,11 @@ func (b *Backup) periodicRunnerFunc(ctx context.Context, t *time.Ticker, eb *api
 				}).Inc()
 
 				// Perform backup
-				bs, err = b.handleBackup(&ctx, &latestEb.Spec, true, latestEb.Namespace)
+				bs, err = b.handleBackup(&ctx, latestEb, true)
 			}
 
 			// Report backup status
-			b.reportBackupStatus(ctx, bs, err, latestEb)
+			b.reportBackupStatus(ctx, bs, err, latestEb, start)
 
 			// If current duration of timer doesn't match expected duration that means we have to revert time to its old state
 			if currentDuration != latestEb.Spec.BackupPolicy.BackupIntervalInSecond {
@@ -260,11 +260,102 @@ func (b *Backup) periodicRunnerFunc(ctx context.Context, t *time.Ticker, eb *api
 	}
 }
 
-func (b *Backup) reportBackupStatus(ctx context.Context, bs *api.BackupStatus, berr error, eb *api.EtcdBackup) {
+// scheduledRunnerFunc takes backups of eb at the times of schedule, until ctx is done.
+// A backup missed while the backup operator was not running is taken right away.
+func (b *Backup) scheduledRunnerFunc(ctx context.Context, schedule *cronutil.Schedule, eb *api.EtcdBackup) {
+	last := eb.CreationTimestamp.Time
+	if !eb.Status.LastExecutionDate.IsZero() {
+		last = eb.Status.LastExecutionDate.Time
+	}
+	next := schedule.Next(last)
+	if !next.IsZero() && next.Before(time.Now()) {
+		b.logger.Infof("EtcdBackup %v missed its backup at %s, taking it now", eb.Name, next)
+		next = time.Now()
+	}
+	// eb may be the object of the informer cache, which must not be modified.
+	latestEb, err := b.getLatestBackup(ctx, eb)
+	if apierrors.IsNotFound(err) {
+		return
+	}
+	if err == nil {
+		latestEb.Status.NextScheduledDate = metav1.NewTime(next)
+		_, err = b.backupCRCli.EtcdV1beta2().EtcdBackups(eb.Namespace).UpdateStatus(ctx, latestEb, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		b.logger.Warningf("failed to update status of backup CR %v : (%v)", eb.Name, err)
+	}
+
+	for {
+		if next.IsZero() {
+			b.logger.Warningf("schedule (%s) of EtcdBackup %v has no next run", eb.Spec.BackupPolicy.Schedule, eb.Name)
+			return
+		}
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		latestEb, err := b.getLatestBackup(ctx, eb)
+		if apierrors.IsNotFound(err) {
+			return
+		}
+		if err != nil {
+			b.logger.Warningf("skipping backup of EtcdBackup %v: %v", eb.Name, err)
+			next = schedule.Next(time.Now())
+			continue
+		}
+		metrics.BackupsAttemptedTotal.With(prometheus.Labels{
+			"namespace": eb.ObjectMeta.Namespace,
+			"name":      eb.ObjectMeta.Name,
+		}).Inc()
+		start := time.Now()
+		bs, err := b.handleBackup(&ctx, latestEb, true)
+		// The next run is computed after the backup, so that a backup taking longer than the schedule skips runs.
+		next = schedule.Next(time.Now())
+		latestEb.Status.NextScheduledDate = metav1.NewTime(next)
+		b.reportBackupStatus(ctx, bs, err, latestEb, start)
+	}
+}
+
+// getLatestBackup gets the latest version of eb, retrying a few times.
+func (b *Backup) getLatestBackup(ctx context.Context, eb *api.EtcdBackup) (*api.EtcdBackup, error) {
+	var latestEb *api.EtcdBackup
+	var err error
+	retryLimit := 5
+	for i := 1; i < retryLimit+1; i++ {
+		latestEb, err = b.backupCRCli.EtcdV1beta2().EtcdBackups(eb.Namespace).Get(ctx, eb.Name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				b.logger.Infof("Could not find EtcdBackup. Stopping periodic backup for EtcdBackup CR %v",
+					eb.Name)
+				break
+			}
+			b.logger.Warningf("[Attempt: %d/%d] Failed to get latest EtcdBackup %v : (%v)",
+				i, retryLimit, eb.Name, err)
+			time.Sleep(1 * time.Second)
+			continue
+		}
+		break
+	}
+	return latestEb, err
+}
+
+// reportBackupStatus updates the status of eb with the result of the backup that started at start.
+func (b *Backup) reportBackupStatus(ctx context.Context, bs *api.BackupStatus, berr error, eb *api.EtcdBackup, start time.Time) {
+	attempt := api.BackupAttempt{
+		StartTime: metav1.NewTime(start),
+		Duration:  metav1.Duration{Duration: time.Since(start).Round(time.Millisecond)},
+	}
 	if berr != nil {
 		eb.Status.Succeeded = false
 		eb.Status.LastExecutionDate = metav1.Now()
+		eb.Status.LastFailureDate = eb.Status.LastExecutionDate
+		eb.Status.ConsecutiveFailures++
 		eb.Status.Reason = berr.Error()
+		attempt.Reason = berr.Error()
 	} else {
 		eb.Status.Reason = ""
 		eb.Status.Succeeded = true
@@ -272,6 +363,13 @@ func (b *Backup) reportBackupStatus(ctx context.Context, bs *api.BackupStatus, b
 		eb.Status.EtcdVersion = bs.EtcdVersion
 		eb.Status.LastSuccessDate = bs.LastSuccessDate
 		eb.Status.LastExecutionDate = bs.LastSuccessDate
+		eb.Status.ConsecutiveFailures = 0
+		if len(bs.History) != 0 {
+			attempt.Path = bs.History[0].Path
+			attempt.Size = bs.History[0].Size
+			attempt.EtcdRevision = bs.History[0].EtcdRevision
+		}
+		attempt.Succeeded = true
 
 		metrics.BackupsSuccessTotal.With(prometheus.Labels{
 			"namespace": eb.ObjectMeta.Namespace,
@@ -282,7 +380,8 @@ func (b *Backup) reportBackupStatus(ctx context.Context, bs *api.BackupStatus, b
 			"name":      eb.ObjectMeta.Name,
 		}).Set(float64(time.Now().Unix()))
 	}
-	_, err := b.backupCRCli.EtcdV1beta2().EtcdBackups(eb.Namespace).Update(ctx, eb, metav1.UpdateOptions{})
+	addBackupAttempt(&eb.Status, attempt)
+	_, err := b.backupCRCli.EtcdV1beta2().EtcdBackups(eb.Namespace).UpdateStatus(ctx, eb, metav1.UpdateOptions{})
 	if err != nil {
 		b.logger.Warningf("failed to update status of backup CR %v : (%v)", eb.Name, err)
 	}
@@ -312,8 +411,26 @@ func (b *Backup) handleErr(err error, key interface{}) {
 	b.logger.Infof("Dropping etcd backup (%v) out of the queue: %v", key, err)
 }
 
-func (b *Backup) handleBackup(parentContext *context.Context, spec *api.BackupSpec, isPeriodic bool, namespace string) (*api.BackupStatus, error) {
-	err := validate(spec)
+// getBackupCluster returns the EtcdCluster referenced by eb, or nil if eb has no reference.
+// It fails if the cluster is not running.
+func (b *Backup) getBackupCluster(ctx context.Context, eb *api.EtcdBackup) (*api.EtcdCluster, error) {
+	ref := eb.Spec.EtcdClusterRef
+	if ref == nil {
+		return nil, nil
+	}
+	ec, err := b.backupCRCli.EtcdV1beta2().EtcdClusters(eb.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get EtcdCluster (%s): %v", ref.Name, err)
+	}
+	if ec.Status.Phase != api.ClusterPhaseRunning {
+		return nil, fmt.Errorf("EtcdCluster (%s) is not running, its phase is %q", ref.Name, ec.Status.Phase)
+	}
+	return ec, nil
+}
+
+func (b *Backup) handleBackup(parentContext *context.Context, eb *api.EtcdBackup, isPeriodic bool) (*api.BackupStatus, error) {
+	spec := &eb.Spec
+	err := spec.Validate()
 	if err != nil {
 		return nil, err
 	}
@@ -334,53 +451,14 @@ func (b *Backup) handleBackup(parentContext *context.Context, spec *api.BackupSp
 	}
 	ctx, cancel := context.WithTimeout(*parentContext, backupTimeout)
 	defer cancel()
-	switch spec.StorageType {
-	case api.BackupStorageTypeS3:
-		bs, err := handleS3(ctx, b.kubecli, spec.S3, spec.EtcdEndpoints, spec.ClientTLSSecret,
-			namespace, isPeriodic, backupMaxCount, spec.AllowSelfSignedCertificates)
-		if err != nil {
-			return nil, err
-		}
-		return bs, nil
-	case api.BackupStorageTypeABS:
-		bs, err := handleABS(ctx, b.kubecli, spec.ABS, spec.EtcdEndpoints, spec.ClientTLSSecret,
-			namespace, isPeriodic, backupMaxCount, spec.AllowSelfSignedCertificates)
-		if err != nil {
-			return nil, err
-		}
-		return bs, nil
-	case api.BackupStorageTypeGCS:
-		bs, err := handleGCS(ctx, b.kubecli, spec.GCS, spec.EtcdEndpoints, spec.ClientTLSSecret,
-			namespace, isPeriodic, backupMaxCount, spec.AllowSelfSignedCertificates)
-		if err != nil {
-			return nil, err
-		}
-		return bs, nil
-	case api.BackupStorageTypeOSS:
-		bs, err := handleOSS(ctx, b.kubecli, spec.OSS, spec.EtcdEndpoints, spec.ClientTLSSecret,
-			namespace, isPeriodic, backupMaxCount, spec.AllowSelfSignedCertificates)
-		if err != nil {
-			return nil, err
-		}
-		return bs, nil
-	default:
-		logrus.Fatalf("unknown StorageType: %v", spec.StorageType)
-	}
-	return nil, nil
-}
-
-// TODO: move this to initializer
-func validate(spec *api.BackupSpec) error {
-	if len(spec.EtcdEndpoints) == 0 {
-		return errors.New("spec.etcdEndpoints should not be empty")
+	ec, err := b.getBackupCluster(ctx, eb)
+	if err != nil {
+		return nil, err
 	}
-	if spec.BackupPolicy != nil {
-		if spec.BackupPolicy.BackupIntervalInSecond < 0 {
-			return errors.New("spec.BackupPolicy.BackupIntervalInSecond should not be lower than 0")
-		}
-		if spec.BackupPolicy.MaxBackups < 0 {
-			return errors.New("spec.BackupPolicy.MaxBackups should not be lower than 0")
-		}
+	bw, path, closeWriter, err := backup.NewWriter(ctx, b.kubecli, eb.Namespace, spec.StorageType, &spec.BackupSource, spec.Encryption)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	defer closeWriter()
+	return saveSnap(ctx, b.kubecli, bw, path, eb, ec, isPeriodic, backupMaxCount)
 }
@@ -15,9 +15,24 @@
 package controller
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/util"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/writer"
+	"github.com/on2itsecurity/etcd-operator/pkg/generated/clientset/versioned/fake"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
 )
 
 func TestValidate(t *testing.T) {
@@ -32,10 +47,74 @@ func TestValidate(t *testing.T) {
 	}, { // fail due to empty etcd endpoints
 		spec:      &api.BackupSpec{},
 		expectErr: true,
+	}, {
+		spec: &api.BackupSpec{
+			EtcdEndpoints: []string{"http://localhost:2379"},
+			BackupPolicy:  &api.BackupPolicy{Schedule: "30 2 * * *", TimeZone: "Europe/Amsterdam"},
+		},
+		expectErr: false,
+	}, { // fail due to both a schedule and an interval
+		spec: &api.BackupSpec{
+			EtcdEndpoints: []string{"http://localhost:2379"},
+			BackupPolicy:  &api.BackupPolicy{Schedule: "@daily", BackupIntervalInSecond: 60},
+		},
+		expectErr: true,
+	}, { // fail due to invalid schedule
+		spec: &api.BackupSpec{
+			EtcdEndpoints: []string{"http://localhost:2379"},
+			BackupPolicy:  &api.BackupPolicy{Schedule: "30 25 * * *"},
+		},
+		expectErr: true,
+	}, { // fail due to unknown time zone
+		spec: &api.BackupSpec{
+			EtcdEndpoints: []string{"http://localhost:2379"},
+			BackupPolicy:  &api.BackupPolicy{Schedule: "@daily", TimeZone: "Nowhere/Special"},
+		},
+		expectErr: true,
+	}, {
+		spec: &api.BackupSpec{
+			EtcdEndpoints: []string{"http://localhost:2379"},
+			BackupPolicy:  &api.BackupPolicy{Schedule: "@hourly", Retention: &api.BackupRetention{Hourly: 24, Daily: 14, Weekly: 8, Monthly: 12}},
+		},
+		expectErr: false,
+	}, { // fail due to negative retention count
+		spec: &api.BackupSpec{
+			EtcdEndpoints: []string{"http://localhost:2379"},
+			BackupPolicy:  &api.BackupPolicy{Schedule: "@hourly", Retention: &api.BackupRetention{Daily: -1}},
+		},
+		expectErr: true,
+	}, { // fail due to time zone without schedule
+		spec: &api.BackupSpec{
+			EtcdEndpoints: []string{"http://localhost:2379"},
+			BackupPolicy:  &api.BackupPolicy{BackupIntervalInSecond: 60, TimeZone: "UTC"},
+		},
+		expectErr: true,
+	}, {
+		spec: &api.BackupSpec{
+			EtcdClusterRef: &api.EtcdClusterRef{Name: "example"},
+		},
+		expectErr: false,
+	}, { // fail due to both a cluster reference and endpoints
+		spec: &api.BackupSpec{
+			EtcdEndpoints:  []string{"http://localhost:2379"},
+			EtcdClusterRef: &api.EtcdClusterRef{Name: "example"},
+		},
+		expectErr: true,
+	}, { // fail due to both a cluster reference and a client TLS secret
+		spec: &api.BackupSpec{
+			EtcdClusterRef:  &api.EtcdClusterRef{Name: "example"},
+			ClientTLSSecret: "example-client-tls",
+		},
+		expectErr: true,
+	}, { // fail due to empty cluster reference
+		spec: &api.BackupSpec{
+			EtcdClusterRef: &api.EtcdClusterRef{},
+		},
+		expectErr: true,
 	}}
 
 	for i, tt := range tests {
-		err := validate(tt.spec)
+		err := tt.spec.Validate()
 		if err != nil && !tt.expectErr {
 			t.Errorf("#%d: validate failed: %v", i, err)
 		}
@@ -44,3 +123,196 @@ func TestValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestReportBackupStatus(t *testing.T) {
+	ctx := context.Background()
+	eb := &api.EtcdBackup{ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "default"}}
+	b := &Backup{logger: logrus.WithField("pkg", "test"), backupCRCli: fake.NewSimpleClientset(eb)}
+	ok := &api.BackupStatus{
+		EtcdRevision:    42,
+		LastSuccessDate: metav1.Now(),
+		History:         []api.BackupAttempt{{Path: "etcd.backup", Size: 512, EtcdRevision: 42}},
+	}
+
+	b.reportBackupStatus(ctx, nil, errors.New("failed"), eb, time.Now())
+	b.reportBackupStatus(ctx, nil, errors.New("failed again"), eb, time.Now())
+	if eb.Status.ConsecutiveFailures != 2 || eb.Status.LastFailureDate.IsZero() {
+		t.Errorf("after failures: consecutiveFailures=%d, lastFailureDate=%v", eb.Status.ConsecutiveFailures, eb.Status.LastFailureDate)
+	}
+	b.reportBackupStatus(ctx, ok, nil, eb, time.Now())
+	if eb.Status.ConsecutiveFailures != 0 || !eb.Status.Succeeded {
+		t.Errorf("after success: consecutiveFailures=%d, succeeded=%v", eb.Status.ConsecutiveFailures, eb.Status.Succeeded)
+	}
+
+	got, err := b.backupCRCli.EtcdV1beta2().EtcdBackups("default").Get(ctx, "backup", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := got.Status.History
+	if len(h) != 3 {
+		t.Fatalf("history has %d attempts, want 3", len(h))
+	}
+	if !h[0].Succeeded || h[0].Path != "etcd.backup" || h[0].Size != 512 || h[0].EtcdRevision != 42 {
+		t.Errorf("unexpected newest attempt: %+v", h[0])
+	}
+	if h[1].Succeeded || h[1].Reason != "failed again" || h[2].Reason != "failed" {
+		t.Errorf("unexpected failed attempts: %+v, %+v", h[1], h[2])
+	}
+
+	for i := 0; i < api.MaxBackupHistory; i++ {
+		b.reportBackupStatus(ctx, ok, nil, eb, time.Now())
+	}
+	if len(eb.Status.History) != api.MaxBackupHistory {
+		t.Errorf("history has %d attempts, want %d", len(eb.Status.History), api.MaxBackupHistory)
+	}
+}
+
+func TestProcessItemInvalidSchedule(t *testing.T) {
+	ctx := context.Background()
+	eb := &api.EtcdBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "default", UID: "backup-uid", Finalizers: []string{"backup-operator-periodic"}},
+		Spec: api.BackupSpec{
+			EtcdEndpoints: []string{"http://localhost:2379"},
+			BackupPolicy:  &api.BackupPolicy{Schedule: "30 25 * * *"},
+		},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(eb); err != nil {
+		t.Fatal(err)
+	}
+	b := &Backup{logger: logrus.WithField("pkg", "test"), indexer: indexer, backupCRCli: fake.NewSimpleClientset(eb.DeepCopy())}
+
+	for i := 0; i < 2; i++ {
+		if err := b.processItem(ctx, "default/backup"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(eb.Status.Reason) != 0 {
+		t.Errorf("cached EtcdBackup is modified: %+v", eb.Status)
+	}
+	got, err := b.backupCRCli.EtcdV1beta2().EtcdBackups("default").Get(ctx, "backup", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Status.History) != 1 || len(got.Status.Reason) == 0 {
+		t.Errorf("invalid schedule get history=%+v, reason=%q, want reported once", got.Status.History, got.Status.Reason)
+	}
+}
+
+func TestEnforceRetentionEvents(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		dryRun  bool
+		wEvents int
+	}{
+		{dryRun: true, wEvents: 1},
+		{dryRun: false, wEvents: 3},
+	}
+	for i, tt := range tests {
+		w := writer.NewLocalWriter(t.TempDir())
+		oldest := util.PeriodicBackupPath("etcd.backup", 3, now.Add(-3*time.Hour))
+		for j := 0; j < 4; j++ {
+			p := util.PeriodicBackupPath("etcd.backup", int64(j), now.Add(-time.Duration(j)*time.Hour))
+			for _, name := range []string{p, util.ManifestPath(p)} {
+				if _, err := w.Write(ctx, name, strings.NewReader(name)); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		bm := backup.NewBackupManagerFromWriter(nil, w, nil, nil, "", "", "", api.BackupCompressionNone)
+		eb := &api.EtcdBackup{ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "default"}}
+		eb.Spec.BackupPolicy = &api.BackupPolicy{Retention: &api.BackupRetention{Hourly: 1, DryRun: tt.dryRun}}
+		kubecli := kubefake.NewSimpleClientset()
+
+		if err := enforceRetention(ctx, kubecli, bm, "etcd.backup", eb, 0, now); err != nil {
+			t.Fatal(err)
+		}
+		// The fake clientset does not generate the names of the events, so count their creations.
+		events := 0
+		for _, a := range kubecli.Actions() {
+			if a.Matches("create", "events") {
+				events++
+				msg := a.(k8stesting.CreateAction).GetObject().(*v1.Event).Message
+				if tt.dryRun && !strings.Contains(msg, oldest) {
+					t.Errorf("#%d: event message %q does not list %s", i, msg, oldest)
+				}
+			}
+		}
+		if events != tt.wEvents {
+			t.Errorf("#%d: %d events, want %d", i, events, tt.wEvents)
+		}
+	}
+}
+
+func TestGetBackupCluster(t *testing.T) {
+	ctx := context.Background()
+	running := &api.EtcdCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "running", Namespace: "default", UID: "1234"},
+		Status:     api.ClusterStatus{Phase: api.ClusterPhaseRunning},
+	}
+	creating := &api.EtcdCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "creating", Namespace: "default"},
+		Status:     api.ClusterStatus{Phase: api.ClusterPhaseCreating},
+	}
+	b := &Backup{logger: logrus.WithField("pkg", "test"), backupCRCli: fake.NewSimpleClientset(running, creating)}
+
+	tests := []struct {
+		ref       *api.EtcdClusterRef
+		wantUID   string
+		expectErr bool
+	}{
+		{nil, "", false},
+		{&api.EtcdClusterRef{Name: "running"}, "1234", false},
+		{&api.EtcdClusterRef{Name: "creating"}, "", true},
+		{&api.EtcdClusterRef{Name: "missing"}, "", true},
+	}
+	for i, tt := range tests {
+		eb := &api.EtcdBackup{
+			ObjectMeta: metav1.ObjectMeta{Name: "backup", Namespace: "default"},
+			Spec:       api.BackupSpec{EtcdClusterRef: tt.ref},
+		}
+		ec, err := b.getBackupCluster(ctx, eb)
+		if err != nil {
+			if !tt.expectErr {
+				t.Errorf("#%d: unexpected error: %v", i, err)
+			}
+			continue
+		}
+		if tt.expectErr {
+			t.Errorf("#%d: expect error, but got nil", i)
+			continue
+		}
+		if tt.ref == nil {
+			if ec != nil {
+				t.Errorf("#%d: expect no cluster, but got %s", i, ec.Name)
+			}
+			continue
+		}
+		if string(ec.UID) != tt.wantUID {
+			t.Errorf("#%d: cluster UID get=%s, want=%s", i, ec.UID, tt.wantUID)
+		}
+	}
+}
+
+func TestClusterClientEndpoint(t *testing.T) {
+	tests := []struct {
+		ec   *api.EtcdCluster
+		want string
+	}{{
+		ec:   &api.EtcdCluster{ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "db"}},
+		want: "http://example-client.db.svc:2379",
+	}, {
+		ec: &api.EtcdCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "db"},
+			Spec:       api.ClusterSpec{TLS: &api.TLSPolicy{Static: &api.StaticTLS{OperatorSecret: "example-operator"}}},
+			Status:     api.ClusterStatus{ServiceName: "etcd", ClientPort: 2390},
+		},
+		want: "https://etcd.db.svc:2390",
+	}}
+	for i, tt := range tests {
+		if got := clusterClientEndpoint(tt.ec); got != tt.want {
+			t.Errorf("#%d: endpoint get=%s, want=%s", i, got, tt.want)
+		}
+	}
+}
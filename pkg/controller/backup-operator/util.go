@@ -18,11 +18,17 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"strings"
+	"time"
 
 	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/writer"
 	"github.com/on2itsecurity/etcd-operator/pkg/util/etcdutil"
 	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
 
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
@@ -44,11 +50,115 @@ func generateTLSConfig(ctx context.Context, kubecli kubernetes.Interface, client
 	return tlsConfig, nil
 }
 
-func isPeriodicBackup(ebSpec *api.BackupSpec) bool {
-	if ebSpec.BackupPolicy != nil {
-		return ebSpec.BackupPolicy.BackupIntervalInSecond != 0
+// saveSnap saves a snapshot of the etcd cluster of eb to path with bw,
+// and prunes old snapshots by the retention policy of eb, or if there are more than maxBackup.
+func saveSnap(ctx context.Context, kubecli kubernetes.Interface, bw writer.Writer, path string, eb *api.EtcdBackup, ec *api.EtcdCluster,
+	isPeriodic bool, maxBackup int) (*api.BackupStatus, error) {
+	spec := &eb.Spec
+	endpoints, clientTLSSecret, clusterName, clusterUID := spec.EtcdEndpoints, spec.ClientTLSSecret, backupClusterName(eb), ""
+	if ec != nil {
+		endpoints = []string{clusterClientEndpoint(ec)}
+		if ec.Spec.TLS.IsSecureClient() {
+			clientTLSSecret = ec.Spec.TLS.OperatorSecret(ec.Name)
+		}
+		clusterName, clusterUID = ec.Name, string(ec.UID)
 	}
-	return false
+	tlsConfig, err := generateTLSConfig(ctx, kubecli, clientTLSSecret, eb.Namespace, spec.AllowSelfSignedCertificates)
+	if err != nil {
+		return nil, err
+	}
+	bm := backup.NewBackupManagerFromWriter(kubecli, bw, tlsConfig, endpoints, eb.Namespace, clusterName, clusterUID, spec.Compression)
+
+	snapPath, m, err := bm.SaveSnap(ctx, path, isPeriodic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save snapshot (%v)", err)
+	}
+	if isPeriodic && spec.BackupPolicy.Retention != nil {
+		if err := enforceRetention(ctx, kubecli, bm, path, eb, maxBackup, m.Timestamp); err != nil {
+			return nil, fmt.Errorf("succeeded in saving snapshot but failed to prune old snapshots (%v)", err)
+		}
+	} else if maxBackup > 0 {
+		err := bm.EnsureMaxBackup(ctx, path, maxBackup)
+		if err != nil {
+			return nil, fmt.Errorf("succeeded in saving snapshot but failed to delete old snapshot (%v)", err)
+		}
+	}
+	// The history holds this backup only, reportBackupStatus adds it to the history of eb.
+	return &api.BackupStatus{
+		EtcdVersion:     m.EtcdVersion,
+		EtcdRevision:    m.EtcdRevision,
+		LastSuccessDate: metav1.NewTime(m.Timestamp),
+		History:         []api.BackupAttempt{{Path: snapPath, Size: m.Size, EtcdRevision: m.EtcdRevision}},
+	}, nil
+}
+
+// enforceRetention prunes the snapshots of path by the retention policy of eb,
+// and records an event for each pruned snapshot. With dry run, it records one event
+// listing the snapshots that would be pruned, and logs all their paths.
+func enforceRetention(ctx context.Context, kubecli kubernetes.Interface, bm *backup.BackupManager, path string, eb *api.EtcdBackup,
+	maxBackup int, now time.Time) error {
+	policy := eb.Spec.BackupPolicy
+	loc, err := time.LoadLocation(policy.TimeZone)
+	if err != nil {
+		return err
+	}
+	pruned, err := bm.EnforceRetention(ctx, path, maxBackup, policy.Retention, now, loc)
+	if err != nil {
+		return err
+	}
+	if policy.Retention.DryRun {
+		if len(pruned) == 0 {
+			return nil
+		}
+		logrus.Infof("retention policy of EtcdBackup %s/%s would prune %d backups, but dry run is set: %s",
+			eb.Namespace, eb.Name, len(pruned), strings.Join(pruned, ", "))
+		_, err := kubecli.CoreV1().Events(eb.Namespace).Create(ctx, k8sutil.BackupsWouldBePrunedEvent(pruned, eb), metav1.CreateOptions{})
+		if err != nil {
+			logrus.Errorf("failed to create backups would be pruned event: %v", err)
+		}
+		return nil
+	}
+	for _, p := range pruned {
+		_, err := kubecli.CoreV1().Events(eb.Namespace).Create(ctx, k8sutil.BackupPrunedEvent(p, eb), metav1.CreateOptions{})
+		if err != nil {
+			logrus.Errorf("failed to create backup pruned event: %v", err)
+		}
+	}
+	return nil
+}
+
+// addBackupAttempt adds a to the history of s, and drops the oldest attempts beyond api.MaxBackupHistory.
+func addBackupAttempt(s *api.BackupStatus, a api.BackupAttempt) {
+	s.History = append([]api.BackupAttempt{a}, s.History...)
+	if len(s.History) > api.MaxBackupHistory {
+		s.History = s.History[:api.MaxBackupHistory]
+	}
+}
+
+// backupClusterName returns the name of the EtcdCluster backed up by eb, from its "etcd_cluster" label.
+// It is empty if the label is not set.
+func backupClusterName(eb *api.EtcdBackup) string {
+	return eb.Labels["etcd_cluster"]
+}
+
+// clusterClientEndpoint returns the endpoint of the client service of ec.
+func clusterClientEndpoint(ec *api.EtcdCluster) string {
+	scheme := "http"
+	if ec.Spec.TLS.IsSecureClient() {
+		scheme = "https"
+	}
+	svc, port := ec.Status.ServiceName, ec.Status.ClientPort
+	if len(svc) == 0 {
+		svc = k8sutil.ClientServiceName(ec.Name, ec.Spec.Service)
+	}
+	if port == 0 {
+		port = k8sutil.EtcdClientPort
+	}
+	return fmt.Sprintf("%s://%s.%s.svc:%d", scheme, svc, ec.Namespace, port)
+}
+
+func isPeriodicBackup(ebSpec *api.BackupSpec) bool {
+	return ebSpec.BackupPolicy.IsPeriodic()
 }
 
 func containsString(slice []string, s string) bool {
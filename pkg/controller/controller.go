@@ -17,6 +17,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
@@ -27,11 +28,36 @@ import (
 	"github.com/sirupsen/logrus"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	kwatch "k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 )
 
 var initRetryWaitTime = 30 * time.Second
 
+// defaultWorkers is the number of clusters handled concurrently if Config.Workers is not set.
+const defaultWorkers = 4
+
+// permanentError is an error of a cluster event that retrying the event cannot fix, e.g. an invalid spec.
+// The event is not retried, the next change of the EtcdCluster is handled again.
+type permanentError struct {
+	reason string
+}
+
+func (pe *permanentError) Error() string {
+	return pe.reason
+}
+
+func newPermanentError(format string, a ...interface{}) error {
+	return &permanentError{fmt.Sprintf(format, a...)}
+}
+
+func isPermanentError(err error) bool {
+	_, ok := err.(*permanentError)
+	return ok
+}
+
 type Event struct {
 	Type   kwatch.EventType
 	Object *api.EtcdCluster
@@ -41,7 +67,13 @@ type Controller struct {
 	logger *logrus.Entry
 	Config
 
-	clusters map[string]*cluster.Cluster
+	// k8s workqueue pattern
+	indexer cache.Indexer
+	queue   workqueue.TypedRateLimitingInterface[any]
+
+	// clustersMu guards clusters, which the workers share.
+	clustersMu sync.Mutex
+	clusters   map[string]*cluster.Cluster
 }
 
 type Config struct {
@@ -51,10 +83,16 @@ type Config struct {
 	KubeCli        kubernetes.Interface
 	KubeExtCli     apiextensionsclient.Interface
 	EtcdCRCli      versioned.Interface
+	DynamicCli     dynamic.Interface
 	CreateCRD      bool
+	// Workers is the number of clusters whose events are handled concurrently.
+	Workers int
 }
 
 func New(cfg Config) *Controller {
+	if cfg.Workers < 1 {
+		cfg.Workers = defaultWorkers
+	}
 	return &Controller{
 		logger: logrus.WithField("pkg", "controller"),
 
@@ -74,62 +112,96 @@ func (c *Controller) handleClusterEvent(event *Event) (bool, error) {
 	if clus.Status.IsFailed() {
 		clustersFailed.Inc()
 		if event.Type == kwatch.Deleted {
+			c.clustersMu.Lock()
 			delete(c.clusters, getNamespacedName(clus))
+			c.clustersMu.Unlock()
 			return false, nil
 		}
-		return false, fmt.Errorf("ignore failed cluster (%s). Please delete its CR", clus.Name)
+		return false, newPermanentError("ignore failed cluster (%s). Please delete its CR", clus.Name)
 	}
 
 	clus.SetDefaults()
 
 	if err := clus.Spec.Validate(); err != nil {
-		return false, fmt.Errorf("invalid cluster spec. please fix the following problem with the cluster spec: %v", err)
+		return false, newPermanentError("invalid cluster spec. please fix the following problem with the cluster spec: %v", err)
 	}
 
+	key := getNamespacedName(clus)
 	switch event.Type {
 	case kwatch.Added:
-		if _, ok := c.clusters[getNamespacedName(clus)]; ok {
+		if c.getCluster(key) != nil {
 			return false, fmt.Errorf("unsafe state. cluster (%s) was created before but we received event (%s)", clus.Name, event.Type)
 		}
+		// A paused cluster is created with its status set in a separate update,
+		// e.g. by the restore operator. Wait for that update before taking it over.
+		if clus.Spec.Paused && clus.Status.Phase == api.ClusterPhaseNone {
+			c.logger.Infof("cluster (%s) is paused and has no status yet, waiting for its status", clus.Name)
+			return false, nil
+		}
 
 		nc := cluster.New(c.makeClusterConfig(), clus)
 		if nc == nil {
-			return false, fmt.Errorf("cluster name cannot be more than %v characters long, please delete the CR\n", k8sutil.MaxNameLength)
+			return false, newPermanentError("cluster name cannot be more than %v characters long, please delete the CR\n", k8sutil.MaxNameLength)
 		}
-		c.clusters[getNamespacedName(clus)] = nc
+		c.setCluster(key, nc)
 
 		clustersCreated.Inc()
 		clustersTotal.Inc()
 
 	case kwatch.Modified:
-		if _, ok := c.clusters[getNamespacedName(clus)]; !ok {
+		cl := c.getCluster(key)
+		if cl == nil {
 			return false, fmt.Errorf("unsafe state. cluster (%s) was never created but we received event (%s)", clus.Name, event.Type)
 		}
-		c.clusters[getNamespacedName(clus)].Update(clus)
+		cl.Update(clus)
 		clustersModified.Inc()
 
 	case kwatch.Deleted:
-		if _, ok := c.clusters[getNamespacedName(clus)]; !ok {
+		if c.getCluster(key) == nil {
 			return false, fmt.Errorf("unsafe state. cluster (%s) was never created but we received event (%s)", clus.Name, event.Type)
 		}
-		c.clusters[getNamespacedName(clus)].Delete()
-		delete(c.clusters, getNamespacedName(clus))
-		clustersDeleted.Inc()
-		clustersTotal.Dec()
+		c.deleteCluster(key)
 	}
 	return false, nil
 }
 
+func (c *Controller) getCluster(key string) *cluster.Cluster {
+	c.clustersMu.Lock()
+	defer c.clustersMu.Unlock()
+	return c.clusters[key]
+}
+
+func (c *Controller) setCluster(key string, cl *cluster.Cluster) {
+	c.clustersMu.Lock()
+	defer c.clustersMu.Unlock()
+	c.clusters[key] = cl
+}
+
+// deleteCluster stops managing the cluster with the given key, if it is managed.
+func (c *Controller) deleteCluster(key string) {
+	c.clustersMu.Lock()
+	cl, ok := c.clusters[key]
+	delete(c.clusters, key)
+	c.clustersMu.Unlock()
+	if !ok {
+		return
+	}
+	cl.Delete()
+	clustersDeleted.Inc()
+	clustersTotal.Dec()
+}
+
 func (c *Controller) makeClusterConfig() cluster.Config {
 	return cluster.Config{
 		ServiceAccount: c.Config.ServiceAccount,
 		KubeCli:        c.Config.KubeCli,
 		EtcdCRCli:      c.Config.EtcdCRCli,
+		DynamicCli:     c.Config.DynamicCli,
 	}
 }
 
 func (c *Controller) initCRD(ctx context.Context) error {
-	err := k8sutil.CreateCRD(ctx, c.KubeExtCli, api.EtcdClusterCRDName, api.EtcdClusterResourceKind, api.EtcdClusterResourcePlural, "etcd")
+	err := k8sutil.CreateCRD(ctx, c.KubeExtCli, api.EtcdClusterCRDName)
 	if err != nil {
 		return fmt.Errorf("failed to create CRD: %v", err)
 	}
@@ -15,13 +15,18 @@
 package controller
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
 	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
 	"github.com/on2itsecurity/etcd-operator/pkg/cluster"
+	"github.com/on2itsecurity/etcd-operator/pkg/generated/clientset/versioned/fake"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/watch"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 )
 
 func TestHandleClusterEventUpdateFailedCluster(t *testing.T) {
@@ -180,3 +185,115 @@ func TestHandleClusterEventWithLongClusterName(t *testing.T) {
 		t.Errorf("cluster should not be ignored")
 	}
 }
+
+func TestHandleClusterEventPausedWithoutStatus(t *testing.T) {
+	c := New(Config{})
+
+	clus := &api.EtcdCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+		},
+		Spec: api.ClusterSpec{
+			Size:   3,
+			Paused: true,
+		},
+	}
+	e := &Event{
+		Type:   watch.Added,
+		Object: clus,
+	}
+	if _, err := c.handleClusterEvent(e); err != nil {
+		t.Fatal(err)
+	}
+	if c.clusters[getNamespacedName(clus)] != nil {
+		t.Errorf("paused cluster without status should not be created")
+	}
+}
+
+func TestProcessItemRecreatedCluster(t *testing.T) {
+	old := &api.EtcdCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "old-uid"},
+		Spec:       api.ClusterSpec{Size: 3, Paused: true},
+		Status:     api.ClusterStatus{Phase: api.ClusterPhaseRunning},
+	}
+	c := New(Config{KubeCli: kubefake.NewSimpleClientset(), EtcdCRCli: fake.NewSimpleClientset(old)})
+	c.indexer = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	key := getNamespacedName(old)
+	cl := cluster.New(c.makeClusterConfig(), old.DeepCopy())
+	c.setCluster(key, cl)
+	defer c.deleteCluster(key)
+
+	// The same EtcdCluster is handed to the cluster created for it.
+	if err := c.indexer.Add(old); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.processItem(key); err != nil {
+		t.Fatal(err)
+	}
+	if c.getCluster(key) != cl {
+		t.Fatal("cluster of the same EtcdCluster is replaced")
+	}
+
+	// The EtcdCluster was deleted and created again, e.g. by the restore operator, before its key was processed.
+	recreated := old.DeepCopy()
+	recreated.UID = "new-uid"
+	recreated.Status = api.ClusterStatus{}
+	if err := c.indexer.Update(recreated); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.processItem(key); err != nil {
+		t.Fatal(err)
+	}
+	// The paused EtcdCluster without status is taken over once its status is set.
+	if c.getCluster(key) != nil {
+		t.Error("cluster of the deleted EtcdCluster is kept")
+	}
+}
+
+func TestHandleErrRequeuesWithBackoff(t *testing.T) {
+	c := New(Config{})
+	c.queue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test")
+	defer c.queue.ShutDown()
+
+	key := "default/test"
+	for i := 0; i < maxRetries; i++ {
+		c.handleErr(errors.New("test"), key)
+	}
+	if n := c.queue.NumRequeues(key); n != maxRetries {
+		t.Errorf("requeues get=%d, want=%d", n, maxRetries)
+	}
+
+	// The key is dropped once it failed too often.
+	c.handleErr(errors.New("test"), key)
+	if n := c.queue.NumRequeues(key); n != 0 {
+		t.Errorf("requeues after dropping get=%d, want=0", n)
+	}
+
+	c.handleErr(errors.New("test"), key)
+	c.handleErr(nil, key)
+	if n := c.queue.NumRequeues(key); n != 0 {
+		t.Errorf("requeues after success get=%d, want=0", n)
+	}
+}
+
+func TestHandleErrForgetsPermanentErrors(t *testing.T) {
+	c := New(Config{})
+	c.queue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test")
+	defer c.queue.ShutDown()
+
+	clus := &api.EtcdCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec:       api.ClusterSpec{Size: 3, Version: "latest"},
+	}
+	_, err := c.handleClusterEvent(&Event{Type: watch.Added, Object: clus})
+	if !isPermanentError(err) {
+		t.Fatalf("invalid spec error get=%v, want a permanent error", err)
+	}
+
+	key := "default/test"
+	c.handleErr(errors.New("test"), key)
+	c.handleErr(err, key)
+	if n := c.queue.NumRequeues(key); n != 0 {
+		t.Errorf("requeues after permanent error get=%d, want=0", n)
+	}
+}
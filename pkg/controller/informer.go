@@ -24,16 +24,21 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/wait"
 	kwatch "k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 )
 
-// TODO: get rid of this once we use workqueue
-var pt *panicTimer
-
-func init() {
-	pt = newPanicTimer(time.Minute, "unexpected long blocking (> 1 Minute) when handling cluster event")
-}
+const (
+	// Copy from deployment_controller.go:
+	// maxRetries is the number of times a cluster event will be retried before it is dropped out of the queue.
+	// With the current rate-limiter in use (5ms*2^(maxRetries-1)) the following numbers represent the times
+	// a cluster event is going to be requeued:
+	//
+	// 5ms, 10ms, 20ms, 40ms, 80ms, 160ms, 320ms, 640ms, 1.3s, 2.6s, 5.1s, 10.2s, 20.4s, 41s, 82s
+	maxRetries = 15
+)
 
 func (c *Controller) Start() error {
 	// TODO: get rid of this init code. CRD and storage class will be managed outside of operator.
@@ -47,11 +52,11 @@ func (c *Controller) Start() error {
 		time.Sleep(initRetryWaitTime)
 	}
 
-	c.run()
+	c.run(context.TODO())
 	panic("unreachable")
 }
 
-func (c *Controller) run() {
+func (c *Controller) run(ctx context.Context) {
 	var ns string
 	if c.Config.ClusterWide {
 		ns = metav1.NamespaceAll
@@ -65,15 +70,28 @@ func (c *Controller) run() {
 		ns,
 		fields.Everything())
 
-	_, informer := cache.NewIndexerInformer(source, &api.EtcdCluster{}, 0, cache.ResourceEventHandlerFuncs{
+	c.queue = workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "etcd-operator")
+	var informer cache.Controller
+	c.indexer, informer = cache.NewIndexerInformer(source, &api.EtcdCluster{}, 0, cache.ResourceEventHandlerFuncs{
 		AddFunc:    c.onAddEtcdClus,
 		UpdateFunc: c.onUpdateEtcdClus,
 		DeleteFunc: c.onDeleteEtcdClus,
 	}, cache.Indexers{})
 
-	ctx := context.TODO()
-	// TODO: use workqueue to avoid blocking
-	informer.Run(ctx.Done())
+	defer c.queue.ShutDown()
+
+	go informer.Run(ctx.Done())
+
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return
+	}
+
+	c.logger.Infof("starting %d workers", c.Config.Workers)
+	for i := 0; i < c.Config.Workers; i++ {
+		go wait.Until(c.runWorker, time.Second, ctx.Done())
+	}
+
+	<-ctx.Done()
 }
 
 func (c *Controller) initResource(ctx context.Context) error {
@@ -87,39 +105,63 @@ func (c *Controller) initResource(ctx context.Context) error {
 }
 
 func (c *Controller) onAddEtcdClus(obj interface{}) {
-	c.syncEtcdClus(obj.(*api.EtcdCluster))
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		panic(err)
+	}
+	c.queue.Add(key)
 }
 
 func (c *Controller) onUpdateEtcdClus(oldObj, newObj interface{}) {
-	c.syncEtcdClus(newObj.(*api.EtcdCluster))
+	key, err := cache.MetaNamespaceKeyFunc(newObj)
+	if err != nil {
+		panic(err)
+	}
+	c.queue.Add(key)
 }
 
 func (c *Controller) onDeleteEtcdClus(obj interface{}) {
-	clus, ok := obj.(*api.EtcdCluster)
-	if !ok {
-		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
-		if !ok {
-			panic(fmt.Sprintf("unknown object from EtcdCluster delete event: %#v", obj))
-		}
-		clus, ok = tombstone.Obj.(*api.EtcdCluster)
-		if !ok {
-			panic(fmt.Sprintf("Tombstone contained object that is not an EtcdCluster: %#v", obj))
-		}
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		panic(err)
 	}
-	ev := &Event{
-		Type:   kwatch.Deleted,
-		Object: clus,
+	c.queue.Add(key)
+}
+
+func (c *Controller) runWorker() {
+	for c.processNextItem() {
 	}
+}
 
-	pt.start()
-	_, err := c.handleClusterEvent(ev)
-	if err != nil {
-		c.logger.Warningf("fail to handle event: %v", err)
+func (c *Controller) processNextItem() bool {
+	// Wait until there is a new item in the working queue
+	key, quit := c.queue.Get()
+	if quit {
+		return false
 	}
-	pt.stop()
+	// Tell the queue that we are done with processing this key. This unblocks the key for other workers
+	// This allows safe parallel processing because two clusters with the same key are never processed in
+	// parallel.
+	defer c.queue.Done(key)
+	err := c.processItem(key.(string))
+	// Handle the error if something went wrong during the execution of the business logic
+	c.handleErr(err, key)
+	return true
 }
 
-func (c *Controller) syncEtcdClus(clus *api.EtcdCluster) {
+// processItem hands the latest state of the EtcdCluster with the given key to its cluster.
+func (c *Controller) processItem(key string) error {
+	obj, exists, err := c.indexer.GetByKey(key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		c.deleteCluster(key)
+		return nil
+	}
+
+	// The cluster is defaulted when it is handled, so it must not be the object in the cache.
+	clus := obj.(*api.EtcdCluster).DeepCopy()
 	ev := &Event{
 		Type:   kwatch.Added,
 		Object: clus,
@@ -127,16 +169,47 @@ func (c *Controller) syncEtcdClus(clus *api.EtcdCluster) {
 	// re-watch or restart could give ADD event.
 	// If for an ADD event the cluster spec is invalid then it is not added to the local cache
 	// so modifying that cluster will result in another ADD event
-	if _, ok := c.clusters[getNamespacedName(clus)]; ok {
-		ev.Type = kwatch.Modified
+	if cl := c.getCluster(key); cl != nil {
+		if cl.UID() == clus.UID {
+			ev.Type = kwatch.Modified
+		} else {
+			// The EtcdCluster was deleted and created again with the same name before its key was processed,
+			// e.g. by the restore operator.
+			c.deleteCluster(key)
+		}
 	}
+	_, err = c.handleClusterEvent(ev)
+	return err
+}
 
-	pt.start()
-	_, err := c.handleClusterEvent(ev)
-	if err != nil {
-		c.logger.Warningf("fail to handle event: %v", err)
+func (c *Controller) handleErr(err error, key interface{}) {
+	if err == nil {
+		// Forget about the #AddRateLimited history of the key on every successful synchronization.
+		// This ensures that future processing of updates for this key is not delayed because of
+		// an outdated error history.
+		c.queue.Forget(key)
+		return
+	}
+
+	if isPermanentError(err) {
+		c.queue.Forget(key)
+		c.logger.Warningf("not retrying event of cluster (%v): %v", key, err)
+		return
 	}
-	pt.stop()
+
+	// This controller retries maxRetries times if something goes wrong. After that, it stops trying.
+	if c.queue.NumRequeues(key) < maxRetries {
+		c.logger.Warningf("fail to handle event of cluster (%v): %v", key, err)
+
+		// Re-enqueue the key rate limited. Based on the rate limiter on the
+		// queue and the re-enqueue history, the key will be processed later again.
+		c.queue.AddRateLimited(key)
+		return
+	}
+
+	c.queue.Forget(key)
+	// Report that, even after several retries, we could not successfully process this key
+	c.logger.Infof("dropping cluster (%v) out of the queue: %v", key, err)
 }
 
 func (c *Controller) managed(clus *api.EtcdCluster) bool {
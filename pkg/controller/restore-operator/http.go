@@ -16,19 +16,24 @@ package controller
 
 import (
 	"context"
+	"crypto/subtle"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"strings"
 
 	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup"
 	"github.com/on2itsecurity/etcd-operator/pkg/backup/backupapi"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/encryption"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/metrics"
 	"github.com/on2itsecurity/etcd-operator/pkg/backup/reader"
-	"github.com/on2itsecurity/etcd-operator/pkg/util/alibabacloudutil/ossfactory"
-	"github.com/on2itsecurity/etcd-operator/pkg/util/awsutil/s3factory"
-	"github.com/on2itsecurity/etcd-operator/pkg/util/azureutil/absfactory"
-	"github.com/on2itsecurity/etcd-operator/pkg/util/gcputil/gcsfactory"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -38,30 +43,68 @@ const (
 	listenAddr     = "0.0.0.0:19999"
 )
 
-func (r *Restore) startHTTP() {
-	http.HandleFunc(backupapi.APIV1+"/backup/", r.handleServeBackup)
+// startHTTPS serves the backups of restores over HTTPS, with the certificate of servingCert.
+func (r *Restore) startHTTPS() {
+	mux := http.NewServeMux()
+	mux.HandleFunc(backupHTTPPath, r.handleServeBackup)
+	srv := &http.Server{
+		Addr:    listenAddr,
+		Handler: mux,
+		TLSConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+				cert, _, err := r.servingCert(hello.Context())
+				return cert, err
+			},
+		},
+	}
 	logrus.Infof("listening on %v", listenAddr)
-	panic(http.ListenAndServe(listenAddr, nil))
+	panic(srv.ListenAndServeTLS("", ""))
 }
 
 func (r *Restore) handleServeBackup(w http.ResponseWriter, req *http.Request) {
-	err := r.serveBackup(req.Context(), w, req)
+	restoreName := req.URL.Path[len(backupHTTPPath):]
+	namespace := req.URL.Query().Get("namespace")
+	labels := prometheus.Labels{"name": restoreName, "namespace": namespace}
+
+	cr, err := r.authorizeDownload(req.Context(), req, restoreName, namespace)
+	if err != nil {
+		// Do not tell the caller whether the restore CR exists.
+		logrus.Warningf("denied backup download of restore CR %s/%s to %s: %v", namespace, restoreName, req.RemoteAddr, err)
+		labels["result"] = "denied"
+		metrics.RestoreDownloadsTotal.With(labels).Inc()
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+
+	n, err := r.serveBackup(req.Context(), w, req, cr)
+	metrics.RestoreDownloadedBytesTotal.With(labels).Add(float64(n))
 	if err != nil {
 		logrus.Error(err)
+		labels["result"] = "failed"
+		metrics.RestoreDownloadsTotal.With(labels).Inc()
+		if n > 0 {
+			// Abort the response, so that the seed member does not restore a partial or corrupted backup.
+			panic(http.ErrAbortHandler)
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
+	logrus.Infof("served backup (%d bytes) of restore CR %s/%s to %s", n, namespace, restoreName, req.RemoteAddr)
+	labels["result"] = "success"
+	metrics.RestoreDownloadsTotal.With(labels).Inc()
 }
 
-// serveBackup parses incoming request url of the form /backup/<restore-name>
-// get the etcd restore name.
-// Then it returns the etcd cluster backup snapshot to the caller.
-func (r *Restore) serveBackup(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
-	restoreName := string(req.URL.Path[len(backupHTTPPath):])
+// authorizeDownload returns the restore CR of a request of the form /backup/<restore-name>?namespace=<namespace>,
+// if it bears the restore token of the restored cluster in its authorization header.
+func (r *Restore) authorizeDownload(ctx context.Context, req *http.Request, restoreName, namespace string) (*api.EtcdRestore, error) {
 	if len(restoreName) == 0 {
-		return errors.New("restore name is not specified")
+		return nil, errors.New("restore name is not specified")
+	}
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok || len(token) == 0 {
+		return nil, errors.New("no restore token")
 	}
-
-	namespace := req.URL.Query().Get("namespace")
 
 	obj := &api.EtcdRestore{
 		ObjectMeta: metav1.ObjectMeta{
@@ -69,109 +112,144 @@ func (r *Restore) serveBackup(ctx context.Context, w http.ResponseWriter, req *h
 			Namespace: namespace,
 		},
 	}
-
 	v, exists, err := r.indexer.Get(obj)
 	if err != nil {
-		return fmt.Errorf("failed to get restore CR for restore-name (%s): %v", restoreName, err)
+		return nil, fmt.Errorf("failed to get restore CR for restore-name (%s): %v", restoreName, err)
 	}
 	if !exists {
-		return fmt.Errorf("no restore CR found for restore-name (%s)", restoreName)
+		return nil, fmt.Errorf("no restore CR found for restore-name (%s)", restoreName)
 	}
-
-	logrus.Infof("serving backup for restore CR %s", restoreName)
 	cr := v.(*api.EtcdRestore)
 
-	var (
-		backupReader reader.Reader
-		path         string
-	)
-
-	switch cr.Spec.BackupStorageType {
-	case api.BackupStorageTypeS3:
-		restoreSource := cr.Spec.RestoreSource
-		if restoreSource.S3 == nil {
-			return errors.New("empty s3 restore source")
-		}
-		s3RestoreSource := restoreSource.S3
-		if len(s3RestoreSource.AWSSecret) == 0 || len(s3RestoreSource.Path) == 0 {
-			return errors.New("invalid s3 restore source field (spec.s3), must specify all required subfields")
-		}
+	rns, clusterName := cr.RestoredCluster()
+	secret, err := r.kubecli.CoreV1().Secrets(rns).Get(ctx, k8sutil.RestoreTokenSecretName(clusterName), metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get restore token: %v", err)
+	}
+	if subtle.ConstantTimeCompare(secret.Data[k8sutil.RestoreTokenKey], []byte(token)) != 1 {
+		return nil, errors.New("invalid restore token")
+	}
 
-		s3Cli, err := s3factory.NewClientFromSecret(ctx, r.kubecli, cr.Namespace, s3RestoreSource.Endpoint, s3RestoreSource.AWSSecret, s3RestoreSource.ForcePathStyle)
+	if cr.Spec.Selector != nil && len(cr.Status.BackupPath) == 0 {
+		// The selected backup may be recorded, but not be in the cache yet.
+		cr, err = r.etcdCRCli.EtcdV1beta2().EtcdRestores(namespace).Get(ctx, restoreName, metav1.GetOptions{})
 		if err != nil {
-			return fmt.Errorf("failed to create S3 client: %v", err)
-		}
-		defer s3Cli.Close()
-
-		backupReader = reader.NewS3Reader(s3Cli.S3)
-		path = s3RestoreSource.Path
-	case api.BackupStorageTypeABS:
-		restoreSource := cr.Spec.RestoreSource
-		if restoreSource.ABS == nil {
-			return errors.New("empty abs restore source")
+			return nil, fmt.Errorf("failed to get restore CR for restore-name (%s): %v", restoreName, err)
 		}
-		absRestoreSource := restoreSource.ABS
-		if len(absRestoreSource.ABSSecret) == 0 || len(absRestoreSource.Path) == 0 {
-			return errors.New("invalid abs restore source field (spec.abs), must specify all required subfields")
+		if len(cr.Status.BackupPath) == 0 {
+			return nil, fmt.Errorf("no backup selected for restore CR (%s)", restoreName)
 		}
+	}
+	return cr, nil
+}
 
-		absCli, err := absfactory.NewClientFromSecret(ctx, r.kubecli, cr.Namespace, absRestoreSource.ABSSecret)
-		if err != nil {
-			return fmt.Errorf("failed to create ABS client: %v", err)
-		}
-		// Nothing to Close for absCli yet
-
-		backupReader = reader.NewABSReader(absCli.BlobClient)
-		path = absRestoreSource.Path
-	case api.BackupStorageTypeGCS:
-		ctx := context.TODO()
-		restoreSource := cr.Spec.RestoreSource
-		if restoreSource.GCS == nil {
-			return errors.New("empty gcs restore source")
-		}
-		gcsRestoreSource := restoreSource.GCS
-		if len(gcsRestoreSource.Path) == 0 {
-			return errors.New("invalid gcs restore source field (spec.gcs), must specify all required subfields")
-		}
+// serveBackup writes the backup of cr to w, and returns the number of bytes written.
+func (r *Restore) serveBackup(ctx context.Context, w http.ResponseWriter, req *http.Request, cr *api.EtcdRestore) (int64, error) {
+	logrus.Infof("serving backup for restore CR %s", cr.Name)
+	backupReader, path, closeReader, err := r.newBackupReader(ctx, cr)
+	if err != nil {
+		return 0, err
+	}
+	defer closeReader()
 
-		gcsCli, err := gcsfactory.NewClientFromSecret(ctx, r.kubecli, cr.Namespace, gcsRestoreSource.GCPSecret)
-		if err != nil {
-			return fmt.Errorf("failed to create GCS client: %v", err)
-		}
-		defer gcsCli.GCS.Close()
-
-		backupReader = reader.NewGCSReader(ctx, gcsCli.GCS)
-		path = gcsRestoreSource.Path
-	case api.BackupStorageTypeOSS:
-		restoreSource := cr.Spec.RestoreSource
-		if restoreSource.OSS == nil {
-			return errors.New("empty oss restore source")
-		}
-		ossRestoreSource := restoreSource.OSS
-		if len(ossRestoreSource.OSSSecret) == 0 || len(ossRestoreSource.Path) == 0 {
-			return errors.New("invalid oss restore source field (spec.oss), must specify all required subfields")
+	m, err := backup.ReadManifest(backupReader, path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return 0, fmt.Errorf("failed to read manifest of backup file(%s): %v", path, err)
 		}
+		logrus.Warningf("serving backup file(%s) without verifying it, it has no manifest", path)
+	}
+
+	rc, err := backupReader.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read backup file(%s): %v", path, err)
+	}
+	defer rc.Close()
+
+	var src io.Reader = rc
+	if m != nil {
+		src = m.NewVerifier(rc)
+	}
+	n, err := io.Copy(w, src)
+	if err != nil {
+		return n, fmt.Errorf("failed to serve backup file(%s) to %s: %v", path, req.RemoteAddr, err)
+	}
+	return n, nil
+}
 
-		ossCli, err := ossfactory.NewClientFromSecret(ctx, r.kubecli, cr.Namespace, ossRestoreSource.Endpoint, ossRestoreSource.OSSSecret)
+// newBackupReader returns the reader of the backup storage of cr, and the path of the backup in it.
+// The reader decrypts and decompresses the backups it opens. closeReader releases the clients of the reader.
+func (r *Restore) newBackupReader(ctx context.Context, cr *api.EtcdRestore) (backupReader reader.Reader, path string, closeReader func(), err error) {
+	sourceData, err := r.secretData(ctx, cr.Namespace, cr.Spec.RestoreSource.Secret(cr.Spec.BackupStorageType))
+	if err != nil {
+		return nil, "", nil, err
+	}
+	var encryptionData map[string][]byte
+	if cr.Spec.Encryption != nil {
+		encryptionData, err = r.secretData(ctx, cr.Namespace, cr.Spec.Encryption.Secret)
 		if err != nil {
-			return fmt.Errorf("failed to create OSS client: %v", err)
+			return nil, "", nil, err
 		}
+	}
+	// The writer of the backup storage lists the backups to select from.
+	backupReader, backupLister, path, closeReader, err := backup.NewReader(ctx, cr.Spec, sourceData, encryptionData, r.backupDir)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	path, err = r.selectBackup(ctx, cr, backupLister, path)
+	if err != nil {
+		closeReader()
+		return nil, "", nil, err
+	}
+	return backupReader, path, closeReader, nil
+}
 
-		backupReader = reader.NewOSSReader(ossCli.OSS)
-		path = ossRestoreSource.Path
-	default:
-		return fmt.Errorf("unknown backup storage type (%s) for restore CR (%v)", cr.Spec.BackupStorageType, restoreName)
+// secretData returns the data of the secret name in namespace, or nil if name is empty.
+func (r *Restore) secretData(ctx context.Context, namespace, name string) (map[string][]byte, error) {
+	if len(name) == 0 {
+		return nil, nil
 	}
+	secret, err := r.kubecli.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret (%s/%s): %v", namespace, name, err)
+	}
+	return secret.Data, nil
+}
+
+// verifyBackup checks the backup of cr against its manifest, and returns its path and manifest.
+// Backups without a manifest, which are taken by older versions of the backup operator, are not verified, and have a nil manifest.
+func (r *Restore) verifyBackup(ctx context.Context, cr *api.EtcdRestore) (path string, m *backup.Manifest, err error) {
+	backupReader, path, closeReader, err := r.newBackupReader(ctx, cr)
+	if err != nil {
+		return "", nil, err
+	}
+	defer closeReader()
 
 	rc, err := backupReader.Open(path)
 	if err != nil {
-		return fmt.Errorf("failed to read backup file(%s): %v", path, err)
+		return "", nil, fmt.Errorf("failed to read backup file(%s): %v", path, err)
 	}
 	defer rc.Close()
 
-	_, err = io.Copy(w, rc)
+	m, err = backup.ReadManifest(backupReader, path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", nil, fmt.Errorf("failed to read manifest of backup file(%s): %v", path, err)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to write backup to %s: %v", req.RemoteAddr, err)
+		// Without a manifest, at least check that the backup can be decrypted, or is not encrypted.
+		start := make([]byte, len(encryption.Magic))
+		if _, rerr := io.ReadFull(rc, start); rerr != nil && rerr != io.ErrUnexpectedEOF {
+			return "", nil, fmt.Errorf("failed to read backup file(%s): %v", path, rerr)
+		}
+		if cr.Spec.Encryption == nil && encryption.IsEncrypted(start) {
+			return "", nil, fmt.Errorf("backup file(%s) is encrypted, spec.encryption must be set", path)
+		}
+		r.logger.Warningf("not verifying backup file(%s), it has no manifest", path)
+		return path, nil, nil
+	}
+
+	if _, err := io.Copy(io.Discard, m.NewVerifier(rc)); err != nil {
+		return "", nil, fmt.Errorf("backup file(%s) does not match its manifest: %v", path, err)
 	}
-	return nil
+	return path, m, nil
 }
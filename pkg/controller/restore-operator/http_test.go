@@ -0,0 +1,105 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http/httptest"
+	"testing"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestAuthorizeDownload(t *testing.T) {
+	ctx := context.Background()
+	cr := &api.EtcdRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "copy", Namespace: "default"},
+		Spec: api.RestoreSpec{
+			EtcdCluster: api.EtcdClusterRef{Name: "example"},
+			Target:      &api.RestoreTarget{Name: "copy", Namespace: "staging"},
+		},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	if err := indexer.Add(cr); err != nil {
+		t.Fatal(err)
+	}
+	secret := k8sutil.NewRestoreTokenSecret("copy", "staging", "secret-token", nil, metav1.OwnerReference{})
+	r := &Restore{indexer: indexer, kubecli: fake.NewSimpleClientset(secret)}
+
+	tests := []struct {
+		name, namespace string
+		auth            string
+		wErr            bool
+	}{
+		{name: "copy", namespace: "default", auth: "Bearer secret-token"},
+		{name: "copy", namespace: "default", wErr: true},
+		{name: "copy", namespace: "default", auth: "Bearer other-token", wErr: true},
+		{name: "copy", namespace: "default", auth: "secret-token", wErr: true},
+		{name: "copy", namespace: "staging", auth: "Bearer secret-token", wErr: true},
+		{name: "", namespace: "default", auth: "Bearer secret-token", wErr: true},
+	}
+	for i, tt := range tests {
+		req := httptest.NewRequest("GET", "/v1/backup/"+tt.name+"?namespace="+tt.namespace, nil)
+		if len(tt.auth) != 0 {
+			req.Header.Set("Authorization", tt.auth)
+		}
+		got, err := r.authorizeDownload(ctx, req, tt.name, tt.namespace)
+		if (err != nil) != tt.wErr {
+			t.Errorf("#%d: error=%v, want error=%v", i, err, tt.wErr)
+		}
+		if err == nil && got.Name != cr.Name {
+			t.Errorf("#%d: restore CR get=%s, want=%s", i, got.Name, cr.Name)
+		}
+	}
+}
+
+func TestServingCert(t *testing.T) {
+	ctx := context.Background()
+	r := &Restore{
+		logger:            logrus.WithField("pkg", "test"),
+		operatorNamespace: "etcd",
+		mySvcAddr:         "etcd-restore-operator.etcd:19999",
+		kubecli:           fake.NewSimpleClientset(),
+	}
+	cert, caPEM, err := r.servingCert(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caPEM) {
+		t.Fatal("invalid CA")
+	}
+	opts := x509.VerifyOptions{DNSName: "etcd-restore-operator.etcd", Roots: roots}
+	if _, err := cert.Leaf.Verify(opts); err != nil {
+		t.Errorf("failed to verify serving certificate: %v", err)
+	}
+
+	// A restarted operator serves the certificate in the secret.
+	r2 := &Restore{operatorNamespace: r.operatorNamespace, kubecli: r.kubecli}
+	cert2, caPEM2, err := r2.servingCert(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cert2.Leaf.Equal(cert.Leaf) || string(caPEM2) != string(caPEM) {
+		t.Error("serving certificate is not reused")
+	}
+}
@@ -16,11 +16,14 @@ package controller
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"sync"
 
 	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
 	"github.com/on2itsecurity/etcd-operator/pkg/client"
 	"github.com/on2itsecurity/etcd-operator/pkg/generated/clientset/versioned"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/constants"
 	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
 
 	"github.com/sirupsen/logrus"
@@ -35,7 +38,17 @@ type Restore struct {
 	logger *logrus.Entry
 
 	namespace string
-	mySvcAddr string
+	// operatorNamespace is the namespace the restore operator runs in.
+	operatorNamespace string
+	mySvcAddr         string
+	// seedImage is the image the seed members of direct restores read their backup with.
+	seedImage string
+	// backupDir is the directory the volume of the Local backup storage is mounted at.
+	backupDir string
+	// the certificate of the backup server, see servingCert.
+	certMu sync.Mutex
+	cert   *tls.Certificate
+	caPEM  []byte
 	// k8s workqueue pattern
 	indexer  cache.Indexer
 	informer cache.Controller
@@ -53,6 +66,8 @@ type Config struct {
 	ClusterWide bool
 	CreateCRD   bool
 	MySvcAddr   string
+	// SeedImage is the image with the etcd-restore-seed command, usually the image of the restore operator.
+	SeedImage string
 }
 
 // New creates a restore operator.
@@ -65,13 +80,16 @@ func New(config Config) *Restore {
 	}
 
 	return &Restore{
-		logger:     logrus.WithField("pkg", "controller"),
-		namespace:  ns,
-		mySvcAddr:  config.MySvcAddr,
-		kubecli:    k8sutil.MustNewKubeClient(),
-		etcdCRCli:  client.MustNewInCluster(),
-		kubeExtCli: k8sutil.MustNewKubeExtClient(),
-		createCRD:  config.CreateCRD,
+		logger:            logrus.WithField("pkg", "controller"),
+		namespace:         ns,
+		operatorNamespace: config.Namespace,
+		mySvcAddr:         config.MySvcAddr,
+		seedImage:         config.SeedImage,
+		backupDir:         constants.BackupMountDir,
+		kubecli:           k8sutil.MustNewKubeClient(),
+		etcdCRCli:         client.MustNewInCluster(),
+		kubeExtCli:        k8sutil.MustNewKubeExtClient(),
+		createCRD:         config.CreateCRD,
 	}
 }
 
@@ -83,14 +101,18 @@ func (r *Restore) Start(ctx context.Context) error {
 		}
 	}
 
+	if _, _, err := r.servingCert(ctx); err != nil {
+		return err
+	}
+
 	go r.run(ctx)
-	go r.startHTTP()
+	go r.startHTTPS()
 	<-ctx.Done()
 	return ctx.Err()
 }
 
 func (r *Restore) initCRD(ctx context.Context) error {
-	err := k8sutil.CreateCRD(ctx, r.kubeExtCli, api.EtcdRestoreCRDName, api.EtcdRestoreResourceKind, api.EtcdRestoreResourcePlural, "")
+	err := k8sutil.CreateCRD(ctx, r.kubeExtCli, api.EtcdRestoreCRDName)
 	if err != nil {
 		return fmt.Errorf("failed to create CRD: %v", err)
 	}
@@ -0,0 +1,175 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// restorePollInterval is how often a restore in progress checks its seed member and restored EtcdCluster.
+const restorePollInterval = 5 * time.Second
+
+// followRestore moves a restore with a seed member to its next phase: Seeding once the seed member fetched the backup,
+// Scaling once the seed member is ready, and Completed once the restored EtcdCluster reached its size.
+// It returns an error if the restore failed. Errors of the API server are logged, and retried at the next poll.
+func (r *Restore) followRestore(ctx context.Context, er *api.EtcdRestore) error {
+	namespace, clusterName := er.RestoredCluster()
+	ec, err := r.etcdCRCli.EtcdV1beta2().EtcdClusters(namespace).Get(ctx, clusterName, metav1.GetOptions{})
+	if err != nil {
+		if k8sutil.IsKubernetesResourceNotFoundError(err) {
+			return fmt.Errorf("restored EtcdCluster (%s/%s) was deleted", namespace, clusterName)
+		}
+		r.logger.Warningf("failed to get restored EtcdCluster (%s/%s): %v", namespace, clusterName, err)
+		return nil
+	}
+
+	var (
+		phase api.RestorePhase
+		msg   string
+	)
+	if er.Status.Phase == api.RestorePhaseScaling {
+		phase, msg, err = restoredClusterPhase(ec)
+		if err != nil {
+			return err
+		}
+	} else {
+		pod, err := r.seedMember(ctx, ec)
+		if err != nil {
+			r.logger.Warningf("failed to get seed member of EtcdCluster (%s/%s): %v", namespace, clusterName, err)
+			return nil
+		}
+		if pod == nil {
+			return fmt.Errorf("seed member of EtcdCluster (%s/%s) was deleted", namespace, clusterName)
+		}
+		phase, msg, err = seedMemberPhase(pod)
+		if err != nil {
+			return err
+		}
+		if phase == api.RestorePhaseScaling {
+			// Patch the spec, so that status updates of the etcd operator do not conflict.
+			_, err = r.etcdCRCli.EtcdV1beta2().EtcdClusters(namespace).Patch(ctx, clusterName, types.MergePatchType, []byte(`{"spec":{"paused":false}}`), metav1.PatchOptions{})
+			if err != nil {
+				r.logger.Warningf("failed to update etcdcluster CR to spec.paused=false: %v", err)
+				return nil
+			}
+			// The seed member restored the backup, and does not download it again.
+			if err := r.deleteRestoreToken(ctx, er); err != nil {
+				r.logger.Warning(err)
+				return nil
+			}
+		}
+	}
+
+	if c := er.Status.Condition(api.RestoreConditionProgressing); phase == er.Status.Phase && c != nil && c.Message == msg {
+		return nil
+	}
+	if phase == api.RestorePhaseCompleted {
+		r.logger.Infof("restore (%s/%s) is completed: %s", er.Namespace, er.Name, msg)
+		er.Status.SetCompleted(msg)
+		err = r.updateStatus(ctx, er)
+	} else {
+		err = r.setPhase(ctx, er, phase, msg)
+	}
+	if err != nil {
+		r.logger.Warning(err)
+	}
+	return nil
+}
+
+// seedMember returns the seed member of the restored EtcdCluster ec, or nil if it does not exist.
+// While ec is paused, it is the only pod owned by ec.
+func (r *Restore) seedMember(ctx context.Context, ec *api.EtcdCluster) (*v1.Pod, error) {
+	pods, err := r.kubecli.CoreV1().Pods(ec.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(k8sutil.LabelsForCluster(ec.Name)).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i := range pods.Items {
+		for _, ref := range pods.Items[i].OwnerReferences {
+			if ref.UID == ec.UID {
+				return &pods.Items[i], nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// seedMemberPhase returns the phase of a restore with the seed member pod, and what it waits for.
+// It returns an error if one of the containers of the seed member failed, which also fails the restore.
+func seedMemberPhase(pod *v1.Pod) (api.RestorePhase, string, error) {
+	statuses := append(append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		for _, t := range []*v1.ContainerStateTerminated{cs.State.Terminated, cs.LastTerminationState.Terminated} {
+			if t == nil || t.ExitCode == 0 {
+				continue
+			}
+			// The restore init containers report their error in their termination message.
+			reason := strings.TrimSpace(t.Message)
+			if len(reason) == 0 {
+				reason = fmt.Sprintf("%s, exit code %d", t.Reason, t.ExitCode)
+			}
+			return "", "", fmt.Errorf("container %s of seed member (%s) failed: %s", cs.Name, pod.Name, reason)
+		}
+	}
+	if pod.Status.Phase == v1.PodFailed {
+		return "", "", fmt.Errorf("seed member (%s) failed: %s", pod.Name, pod.Status.Message)
+	}
+
+	if k8sutil.IsPodReady(pod) {
+		return api.RestorePhaseScaling, "Waiting for the etcd operator to scale the restored EtcdCluster", nil
+	}
+	for _, cs := range pod.Status.InitContainerStatuses {
+		if cs.Name == k8sutil.FetchBackupContainerName && cs.State.Terminated != nil {
+			return api.RestorePhaseSeeding, fmt.Sprintf("Waiting for seed member (%s) to restore the backup and become ready", pod.Name), nil
+		}
+	}
+	msg := fmt.Sprintf("Waiting for seed member (%s) to fetch the backup", pod.Name)
+	// Point out why the seed member does not start, e.g. a missing secret.
+	for _, cs := range statuses {
+		if w := cs.State.Waiting; w != nil && w.Reason != "PodInitializing" && w.Reason != "ContainerCreating" {
+			msg += fmt.Sprintf(", container %s is waiting: %s", cs.Name, w.Reason)
+			if len(w.Message) != 0 {
+				msg += ": " + w.Message
+			}
+			break
+		}
+	}
+	return api.RestorePhaseFetching, msg, nil
+}
+
+// restoredClusterPhase returns the phase of a restore with the restored EtcdCluster ec, and what it waits for.
+// It returns an error if ec failed, which also fails the restore.
+func restoredClusterPhase(ec *api.EtcdCluster) (api.RestorePhase, string, error) {
+	if ec.Status.IsFailed() {
+		return "", "", fmt.Errorf("restored EtcdCluster (%s/%s) failed: %s", ec.Namespace, ec.Name, ec.Status.Reason)
+	}
+	ready := len(ec.Status.Members.Ready)
+	if !ec.Spec.Paused && ready >= ec.Spec.Size && len(ec.Status.Members.Unready) == 0 {
+		return api.RestorePhaseCompleted, fmt.Sprintf("Restored EtcdCluster (%s/%s) has %d ready members", ec.Namespace, ec.Name, ready), nil
+	}
+	return api.RestorePhaseScaling, fmt.Sprintf("%d of %d members of the restored EtcdCluster are ready", ready, ec.Spec.Size), nil
+}
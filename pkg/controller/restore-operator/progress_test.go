@@ -0,0 +1,175 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/generated/clientset/versioned/fake"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
+
+	"github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func newSeedMember(ready bool, initStatuses ...v1.ContainerStatus) *v1.Pod {
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "example-seed"}}
+	pod.Status.InitContainerStatuses = initStatuses
+	if ready {
+		pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: v1.ConditionTrue}}
+	}
+	return pod
+}
+
+func terminated(name string, exitCode int32, msg string) v1.ContainerStatus {
+	return v1.ContainerStatus{
+		Name:  name,
+		State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: exitCode, Message: msg}},
+	}
+}
+
+func TestSeedMemberPhase(t *testing.T) {
+	fetched := terminated(k8sutil.FetchBackupContainerName, 0, "")
+	restored := terminated("restore-datadir", 0, "")
+	missingSecret := v1.ContainerStatus{
+		Name:  k8sutil.FetchBackupContainerName,
+		State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{Reason: "CreateContainerConfigError"}},
+	}
+	restarted := v1.ContainerStatus{
+		Name:                 k8sutil.FetchBackupContainerName,
+		LastTerminationState: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{ExitCode: 22}},
+	}
+	failedPod := newSeedMember(false)
+	failedPod.Status.Phase = v1.PodFailed
+
+	tests := []struct {
+		pod    *v1.Pod
+		wPhase api.RestorePhase
+		wErr   bool
+	}{
+		{pod: newSeedMember(false), wPhase: api.RestorePhaseFetching},
+		{pod: newSeedMember(false, missingSecret), wPhase: api.RestorePhaseFetching},
+		{pod: newSeedMember(false, fetched), wPhase: api.RestorePhaseSeeding},
+		{pod: newSeedMember(true, fetched, restored), wPhase: api.RestorePhaseScaling},
+		{pod: newSeedMember(false, terminated(k8sutil.FetchBackupContainerName, 1, "backup not found")), wErr: true},
+		{pod: newSeedMember(false, fetched, terminated("restore-datadir", 1, "")), wErr: true},
+		{pod: newSeedMember(false, restarted), wErr: true},
+		{pod: failedPod, wErr: true},
+	}
+	for i, tt := range tests {
+		phase, _, err := seedMemberPhase(tt.pod)
+		if phase != tt.wPhase || (err != nil) != tt.wErr {
+			t.Errorf("#%d: get=%s, %v, want=%s, error=%v", i, phase, err, tt.wPhase, tt.wErr)
+		}
+	}
+}
+
+func TestRestoredClusterPhase(t *testing.T) {
+	newCluster := func(paused bool, ready, unready []string) *api.EtcdCluster {
+		ec := &api.EtcdCluster{ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"}}
+		ec.Spec.Size = 3
+		ec.Spec.Paused = paused
+		ec.Status.Phase = api.ClusterPhaseRunning
+		ec.Status.Members = api.MembersStatus{Ready: ready, Unready: unready}
+		return ec
+	}
+	failed := newCluster(false, nil, nil)
+	failed.Status.Phase = api.ClusterPhaseFailed
+
+	tests := []struct {
+		ec     *api.EtcdCluster
+		wPhase api.RestorePhase
+		wErr   bool
+	}{
+		{ec: newCluster(false, []string{"a"}, []string{"b"}), wPhase: api.RestorePhaseScaling},
+		{ec: newCluster(false, []string{"a", "b"}, []string{"c"}), wPhase: api.RestorePhaseScaling},
+		{ec: newCluster(true, []string{"a", "b", "c"}, nil), wPhase: api.RestorePhaseScaling},
+		{ec: newCluster(false, []string{"a", "b", "c"}, nil), wPhase: api.RestorePhaseCompleted},
+		{ec: failed, wErr: true},
+	}
+	for i, tt := range tests {
+		phase, _, err := restoredClusterPhase(tt.ec)
+		if phase != tt.wPhase || (err != nil) != tt.wErr {
+			t.Errorf("#%d: get=%s, %v, want=%s, error=%v", i, phase, err, tt.wPhase, tt.wErr)
+		}
+	}
+}
+
+func TestFollowRestore(t *testing.T) {
+	ctx := context.Background()
+	er := &api.EtcdRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default"},
+		Spec:       api.RestoreSpec{EtcdCluster: api.EtcdClusterRef{Name: "example"}},
+	}
+	er.Status.SetPhase(api.RestorePhaseSeeding, "")
+	ec := &api.EtcdCluster{ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default", UID: "example-uid"}}
+	ec.Spec.Size = 1
+	ec.Spec.Paused = true
+	pod := newSeedMember(true, terminated(k8sutil.FetchBackupContainerName, 0, ""))
+	pod.Namespace = "default"
+	pod.Labels = k8sutil.LabelsForCluster("example")
+	pod.OwnerReferences = []metav1.OwnerReference{ec.AsOwner()}
+	token := k8sutil.NewRestoreTokenSecret("example", "default", "token", nil, ec.AsOwner())
+	etcdCRCli := fake.NewSimpleClientset(er, ec)
+	r := &Restore{
+		logger:    logrus.WithField("pkg", "test"),
+		kubecli:   kubefake.NewSimpleClientset(pod, token),
+		etcdCRCli: etcdCRCli,
+	}
+	clusters := etcdCRCli.EtcdV1beta2().EtcdClusters("default")
+
+	// The ready seed member hands the restored EtcdCluster over to the etcd operator.
+	if err := r.followRestore(ctx, er); err != nil {
+		t.Fatal(err)
+	}
+	if er.Status.Phase != api.RestorePhaseScaling {
+		t.Fatalf("phase get=%s, want=%s", er.Status.Phase, api.RestorePhaseScaling)
+	}
+	ec, err := clusters.Get(ctx, "example", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ec.Spec.Paused {
+		t.Error("restored EtcdCluster is still paused")
+	}
+	_, err = r.kubecli.CoreV1().Secrets("default").Get(ctx, token.Name, metav1.GetOptions{})
+	if !k8sutil.IsKubernetesResourceNotFoundError(err) {
+		t.Errorf("restore token of the ready seed member get err=%v, want not found", err)
+	}
+
+	// The restore completes once the etcd operator reports the cluster at its size.
+	ec.Status.Phase = api.ClusterPhaseRunning
+	ec.Status.Members.Ready = []string{pod.Name}
+	if _, err := clusters.UpdateStatus(ctx, ec, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.followRestore(ctx, er); err != nil {
+		t.Fatal(err)
+	}
+	got, err := etcdCRCli.EtcdV1beta2().EtcdRestores("default").Get(ctx, "example", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.Phase != api.RestorePhaseCompleted || !got.Status.Succeeded || got.Status.CompletionTime == nil {
+		t.Errorf("restore status get=%+v, want completed", got.Status)
+	}
+	if c := got.Status.Condition(api.RestoreConditionComplete); c == nil || c.Status != v1.ConditionTrue {
+		t.Errorf("complete condition get=%+v, want true", c)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/util"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/writer"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// selectBackup returns the path of the backup to restore for cr, from the backups at basePath in the storage listed by w.
+// Once a backup is selected and recorded in the status of cr, that backup is restored,
+// even if newer backups are saved meanwhile.
+func (r *Restore) selectBackup(ctx context.Context, cr *api.EtcdRestore, w writer.Writer, basePath string) (string, error) {
+	sel := cr.Spec.Selector
+	if sel == nil {
+		return basePath, nil
+	}
+	if len(cr.Status.BackupPath) != 0 {
+		return cr.Status.BackupPath, nil
+	}
+	if len(sel.EtcdBackup) != 0 {
+		eb, err := r.etcdCRCli.EtcdV1beta2().EtcdBackups(cr.Namespace).Get(ctx, sel.EtcdBackup, metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf("failed to get EtcdBackup(%s/%s): %v", cr.Namespace, sel.EtcdBackup, err)
+		}
+		return lastBackupPath(eb, cr.Spec.BackupStorageType, basePath)
+	}
+
+	paths, err := w.List(ctx, basePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to list backups at %s: %v", basePath, err)
+	}
+	path, ok := selectBackupPath(paths, basePath, sel)
+	if !ok {
+		return "", fmt.Errorf("no backup at %s matches spec.selector", basePath)
+	}
+	return path, nil
+}
+
+// selectBackupPath returns the newest backup saved at basePath among paths that sel selects.
+// Paths of backups saved at other base paths with the same prefix are skipped.
+// It returns false if no backup is selected.
+func selectBackupPath(paths []string, basePath string, sel *api.RestoreSelector) (string, bool) {
+	backups := []string{}
+	for _, p := range paths {
+		if util.IsManifestPath(p) || !util.IsBackupPathOf(p, basePath) {
+			continue
+		}
+		if sel.Before != nil {
+			t, ok := util.BackupTimestamp(p)
+			if !ok || !t.Before(sel.Before.Time) {
+				continue
+			}
+		}
+		if sel.EtcdRevision != 0 {
+			rev, ok := util.BackupRevision(p)
+			if !ok || rev != sel.EtcdRevision {
+				continue
+			}
+		}
+		backups = append(backups, p)
+	}
+	if len(backups) == 0 {
+		return "", false
+	}
+	sort.Sort(util.SortableBackupPaths(backups))
+	return backups[len(backups)-1], true
+}
+
+// lastBackupPath returns the path of the last backup eb saved, which must be in storage of type st at basePath.
+func lastBackupPath(eb *api.EtcdBackup, st api.BackupStorageType, basePath string) (string, error) {
+	if eb.Spec.StorageType != st {
+		return "", fmt.Errorf("EtcdBackup(%s) saves its backups in %s, not in %s", eb.Name, eb.Spec.StorageType, st)
+	}
+	for _, a := range eb.Status.History {
+		if !a.Succeeded || len(a.Path) == 0 {
+			continue
+		}
+		if !util.IsBackupPathOf(a.Path, basePath) {
+			return "", fmt.Errorf("last backup(%s) of EtcdBackup(%s) is not at %s", a.Path, eb.Name, basePath)
+		}
+		return a.Path, nil
+	}
+	return "", fmt.Errorf("EtcdBackup(%s) has no successful backup", eb.Name)
+}
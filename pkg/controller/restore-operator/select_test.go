@@ -0,0 +1,91 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/util"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSelectBackupPath(t *testing.T) {
+	t0 := time.Date(2026, 3, 4, 5, 0, 0, 0, time.UTC)
+	oldest := util.PeriodicBackupPath("bucket/etcd.backup", 10, t0)
+	middle := util.PeriodicBackupPath("bucket/etcd.backup", 20, t0.Add(time.Hour))
+	newest := util.PeriodicBackupPath("bucket/etcd.backup", 20, t0.Add(2*time.Hour))
+	// The backups of another cluster whose base path starts with the same prefix are not selected.
+	sibling := util.PeriodicBackupPath("bucket/etcd.backup-staging", 20, t0.Add(3*time.Hour))
+	paths := []string{middle, util.ManifestPath(newest), newest, oldest, util.ManifestPath(oldest), sibling}
+	before := metav1.NewTime(t0.Add(90 * time.Minute))
+
+	tests := []struct {
+		sel   api.RestoreSelector
+		wPath string
+		wOk   bool
+	}{
+		{sel: api.RestoreSelector{Latest: true}, wPath: newest, wOk: true},
+		{sel: api.RestoreSelector{Before: &before}, wPath: middle, wOk: true},
+		{sel: api.RestoreSelector{Before: &metav1.Time{Time: t0}}},
+		{sel: api.RestoreSelector{EtcdRevision: 10}, wPath: oldest, wOk: true},
+		{sel: api.RestoreSelector{EtcdRevision: 20}, wPath: newest, wOk: true},
+		{sel: api.RestoreSelector{EtcdRevision: 30}},
+	}
+	for i, tt := range tests {
+		path, ok := selectBackupPath(paths, "bucket/etcd.backup", &tt.sel)
+		if path != tt.wPath || ok != tt.wOk {
+			t.Errorf("#%d: get=%s, %v, want=%s, %v", i, path, ok, tt.wPath, tt.wOk)
+		}
+	}
+}
+
+func TestLastBackupPath(t *testing.T) {
+	t0 := time.Date(2026, 3, 4, 5, 0, 0, 0, time.UTC)
+	last := util.PeriodicBackupPath("bucket/etcd.backup", 20, t0.Add(time.Hour))
+	eb := &api.EtcdBackup{
+		ObjectMeta: metav1.ObjectMeta{Name: "example"},
+		Spec:       api.BackupSpec{StorageType: api.BackupStorageTypeS3},
+		Status: api.BackupStatus{History: []api.BackupAttempt{
+			{Reason: "failed"},
+			{Path: last, Succeeded: true},
+			{Path: util.PeriodicBackupPath("bucket/etcd.backup", 10, t0), Succeeded: true},
+		}},
+	}
+	failing := eb.DeepCopy()
+	failing.Status.History = failing.Status.History[:1]
+
+	tests := []struct {
+		eb       *api.EtcdBackup
+		st       api.BackupStorageType
+		basePath string
+		wPath    string
+		wErr     bool
+	}{
+		{eb: eb, st: api.BackupStorageTypeS3, basePath: "bucket/etcd.backup", wPath: last},
+		{eb: eb, st: api.BackupStorageTypeGCS, basePath: "bucket/etcd.backup", wErr: true},
+		{eb: eb, st: api.BackupStorageTypeS3, basePath: "other/etcd.backup", wErr: true},
+		{eb: eb, st: api.BackupStorageTypeS3, basePath: "bucket/etcd", wErr: true},
+		{eb: failing, st: api.BackupStorageTypeS3, basePath: "bucket/etcd.backup", wErr: true},
+	}
+	for i, tt := range tests {
+		path, err := lastBackupPath(tt.eb, tt.st, tt.basePath)
+		if path != tt.wPath || (err != nil) != tt.wErr {
+			t.Errorf("#%d: get=%s, %v, want=%s, error=%v", i, path, err, tt.wPath, tt.wErr)
+		}
+	}
+}
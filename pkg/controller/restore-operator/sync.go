@@ -16,15 +16,16 @@ package controller
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
+	"maps"
 
 	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
 	"github.com/on2itsecurity/etcd-operator/pkg/backup/backupapi"
+	"github.com/on2itsecurity/etcd-operator/pkg/backup/util"
 	"github.com/on2itsecurity/etcd-operator/pkg/util/etcdutil"
 	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
-	"github.com/on2itsecurity/etcd-operator/pkg/util/retryutil"
 
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -67,41 +68,69 @@ func (r *Restore) processItem(ctx context.Context, key string) error {
 	if !exists {
 		return nil
 	}
-	return r.handleCR(ctx, obj.(*api.EtcdRestore))
+	cached := obj.(*api.EtcdRestore)
+	if cached.Status.IsFinished() {
+		return nil
+	}
+	// The cache may not have the phase recorded last yet, and the seed must not be prepared twice.
+	er, err := r.etcdCRCli.EtcdV1beta2().EtcdRestores(cached.Namespace).Get(ctx, cached.Name, metav1.GetOptions{})
+	if err != nil {
+		if k8sutil.IsKubernetesResourceNotFoundError(err) {
+			return nil
+		}
+		return err
+	}
+	return r.handleCR(ctx, key, er)
 }
 
-// handleCR takes in EtcdRestore CR and prepares the seed so that etcd operator can take over it later.
-func (r *Restore) handleCR(ctx context.Context, er *api.EtcdRestore) (err error) {
-	// don't process the CR if it has a status since
-	// having a status means that the restore is either made or failed.
-	if er.Status.Succeeded || len(er.Status.Reason) != 0 {
+// handleCR moves the restore of the EtcdRestore CR through its phases. It prepares the seed at once,
+// and then polls the seed member and the restored EtcdCluster until the cluster reaches its size.
+func (r *Restore) handleCR(ctx context.Context, key string, er *api.EtcdRestore) error {
+	// don't process the CR if it is finished, since
+	// having a finished status means that the restore is either made or failed.
+	if er.Status.IsFinished() {
 		return nil
 	}
 
-	defer r.reportStatus(ctx, err, er)
-	// NOTE: Since the restore EtcdCluster is created with the same name as the EtcdClusterRef,
-	// the seed member will send a request of the form /backup/<cluster-name> to the backup server.
-	// The EtcdRestore CR name must be the same as the EtcdCluster name in order for the backup server
-	// to successfully lookup the EtcdRestore CR associated with this <cluster-name>.
-	if er.Name != er.Spec.EtcdCluster.Name {
-		err = fmt.Errorf("failed to handle restore CR: EtcdRestore CR name(%v) must be the same as EtcdCluster name(%v)", er.Name, er.Spec.EtcdCluster.Name)
-		return err
+	var err error
+	switch er.Status.Phase {
+	case api.RestorePhaseNone, api.RestorePhaseValidating, api.RestorePhaseDeleting:
+		// A restore the restore operator was stopped in the middle of is prepared again from the start.
+		err = r.prepareSeed(ctx, er)
+	case api.RestorePhaseFetching, api.RestorePhaseSeeding, api.RestorePhaseScaling:
+		err = r.followRestore(ctx, er)
+	default:
+		err = fmt.Errorf("unknown restore phase %s", er.Status.Phase)
 	}
-	err = r.prepareSeed(ctx, er)
-	return err
+	if err != nil {
+		r.logger.Errorf("restore (%s/%s) failed: %v", er.Namespace, er.Name, err)
+		if err := r.deleteRestoreToken(ctx, er); err != nil {
+			r.logger.Warning(err)
+		}
+		er.Status.SetFailed(err)
+		return r.updateStatus(ctx, er)
+	}
+	if !er.Status.IsFinished() {
+		r.queue.AddAfter(key, restorePollInterval)
+	}
+	return nil
 }
 
-func (r *Restore) reportStatus(ctx context.Context, rerr error, er *api.EtcdRestore) {
-	if rerr != nil {
-		er.Status.Succeeded = false
-		er.Status.Reason = rerr.Error()
-	} else {
-		er.Status.Succeeded = true
-	}
-	_, err := r.etcdCRCli.EtcdV1beta2().EtcdRestores(er.Namespace).Update(ctx, er, metav1.UpdateOptions{})
+// setPhase moves er to phase p in progress, and records it in the status of er.
+func (r *Restore) setPhase(ctx context.Context, er *api.EtcdRestore, p api.RestorePhase, msg string) error {
+	r.logger.Infof("restore (%s/%s) is in phase %s: %s", er.Namespace, er.Name, p, msg)
+	er.Status.SetPhase(p, msg)
+	return r.updateStatus(ctx, er)
+}
+
+// updateStatus records the status of er, and moves er to the new resource version.
+func (r *Restore) updateStatus(ctx context.Context, er *api.EtcdRestore) error {
+	updated, err := r.etcdCRCli.EtcdV1beta2().EtcdRestores(er.Namespace).UpdateStatus(ctx, er, metav1.UpdateOptions{})
 	if err != nil {
-		r.logger.Warningf("failed to update status of restore CR %v : (%v)", er.Name, err)
+		return fmt.Errorf("failed to update status of restore CR (%s): %v", er.Name, err)
 	}
+	er.ResourceVersion = updated.ResourceVersion
+	return nil
 }
 
 func (r *Restore) handleErr(err error, key interface{}) {
@@ -129,19 +158,30 @@ func (r *Restore) handleErr(err error, key interface{}) {
 }
 
 // prepareSeed does the following:
-// - fetches and deletes the reference EtcdCluster CR
+// - validates the CR, in the Validating phase
+// - fetches the reference EtcdCluster CR
+// - selects the backup, if spec.selector is set
+// - verifies the backup against its manifest
+// - deletes the reference EtcdCluster CR, in the Deleting phase
+//   - unless a target is set, which leaves the reference EtcdCluster CR running
+//
 // - creates new EtcdCluster CR with same metadata and spec as the reference CR
-// - and spec.paused=true and status.phase="Running"
+//   - or with the target name and namespace, and without the owners and backup policy of the reference CR
+//   - annotated with the UID of the restore CR, see restoredBy
+//   - and spec.paused=true and status.phase="Running"
 //   - spec.paused=true: keep operator from touching membership
 //   - status.phase=Running:
 //     1. expect operator to setup the services
 //     2. make operator ignore the "create seed member" phase
 //
+// - records the backup in the status, in the Fetching phase
 // - create seed member that would restore data from backup
 //   - ownerRef to above EtcdCluster CR
 //
-// - update EtcdCluster CR spec.paused=false
-//   - etcd operator should pick up the membership and scale the etcd cluster
+// followRestore sets spec.paused=false once the seed member is ready.
+//
+// prepareSeed may run again for a restore the restore operator was stopped in the middle of.
+// It then takes over the restored EtcdCluster CR it already created, instead of deleting or refusing it.
 func (r *Restore) prepareSeed(ctx context.Context, er *api.EtcdRestore) (err error) {
 	defer func() {
 		if err != nil {
@@ -149,6 +189,13 @@ func (r *Restore) prepareSeed(ctx context.Context, er *api.EtcdRestore) (err err
 		}
 	}()
 
+	if err = r.setPhase(ctx, er, api.RestorePhaseValidating, "Checking the reference EtcdCluster and the backup"); err != nil {
+		return err
+	}
+	if err = er.Validate(); err != nil {
+		return fmt.Errorf("invalid restore CR: %v", err)
+	}
+
 	// Fetch the reference EtcdCluster
 	ecRef := er.Spec.EtcdCluster
 	ec, err := r.etcdCRCli.EtcdV1beta2().EtcdClusters(er.Namespace).Get(ctx, ecRef.Name, metav1.GetOptions{})
@@ -158,65 +205,149 @@ func (r *Restore) prepareSeed(ctx context.Context, er *api.EtcdRestore) (err err
 	if err := ec.Spec.Validate(); err != nil {
 		return fmt.Errorf("invalid cluster spec: %v", err)
 	}
-
-	// Delete reference EtcdCluster
-	err = r.etcdCRCli.EtcdV1beta2().EtcdClusters(er.Namespace).Delete(ctx, ecRef.Name, metav1.DeleteOptions{})
+	namespace, clusterName := er.RestoredCluster()
+	// A resumed restore takes over the restored EtcdCluster it created already.
+	// Without a target, that replaced the reference EtcdCluster.
+	var restored *api.EtcdCluster
+	if er.Spec.Target == nil {
+		if restoredBy(ec, er) {
+			restored = ec
+		}
+	} else {
+		target, err := r.etcdCRCli.EtcdV1beta2().EtcdClusters(namespace).Get(ctx, clusterName, metav1.GetOptions{})
+		switch {
+		case err == nil && restoredBy(target, er):
+			restored = target
+		case err == nil:
+			return fmt.Errorf("target EtcdCluster (%s/%s) already exists", namespace, clusterName)
+		case !k8sutil.IsKubernetesResourceNotFoundError(err):
+			return fmt.Errorf("failed to get target EtcdCluster (%s/%s): %v", namespace, clusterName, err)
+		}
+	}
+	// Refuse a corrupted backup before the reference EtcdCluster is deleted.
+	path, m, err := r.verifyBackup(ctx, er)
 	if err != nil {
-		return fmt.Errorf("failed to delete reference EtcdCluster (%s/%s): %v", er.Namespace, ecRef.Name, err)
+		return err
+	}
+	er.Status.BackupPath = path
+	if m != nil {
+		er.Status.BackupSHA256 = m.SHA256
+		er.Status.EtcdRevision = m.EtcdRevision
+	} else if rev, ok := util.BackupRevision(path); ok {
+		er.Status.EtcdRevision = rev
 	}
-	// Need to delete etcd pods, etc. completely before creating new cluster.
-	r.deleteClusterResourcesCompletely(ctx, er.Namespace, ecRef.Name)
 
-	// Create the restored EtcdCluster with the same metadata and spec as reference EtcdCluster
-	clusterName := ecRef.Name
-	ec = &api.EtcdCluster{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:            clusterName,
-			Labels:          ec.ObjectMeta.Labels,
-			Annotations:     ec.ObjectMeta.Annotations,
-			OwnerReferences: ec.ObjectMeta.OwnerReferences,
-		},
-		Spec: ec.Spec,
+	if restored == nil {
+		if er.Spec.Target != nil {
+			ec = newTargetCluster(ec, namespace, clusterName)
+		} else {
+			err = r.setPhase(ctx, er, api.RestorePhaseDeleting, fmt.Sprintf("Deleting the reference EtcdCluster (%s/%s)", er.Namespace, ecRef.Name))
+			if err != nil {
+				return err
+			}
+			// Delete reference EtcdCluster
+			err = r.etcdCRCli.EtcdV1beta2().EtcdClusters(er.Namespace).Delete(ctx, ecRef.Name, metav1.DeleteOptions{})
+			if err != nil && !k8sutil.IsKubernetesResourceNotFoundError(err) {
+				return fmt.Errorf("failed to delete reference EtcdCluster (%s/%s): %v", er.Namespace, ecRef.Name, err)
+			}
+			// Need to delete etcd pods, etc. completely before creating new cluster.
+			r.deleteClusterResourcesCompletely(ctx, er.Namespace, ecRef.Name)
+
+			// Create the restored EtcdCluster with the same metadata and spec as reference EtcdCluster
+			ec = &api.EtcdCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            clusterName,
+					Namespace:       namespace,
+					Labels:          ec.ObjectMeta.Labels,
+					Annotations:     ec.ObjectMeta.Annotations,
+					OwnerReferences: ec.ObjectMeta.OwnerReferences,
+				},
+				Spec: ec.Spec,
+			}
+		}
+		restored, err = r.createRestoredCluster(ctx, ec, er)
+		if err != nil {
+			return err
+		}
 	}
+	ec = restored
 
-	ec.Spec.Paused = true
+	// The status is ignored on create. The etcd operator waits for it while the cluster is paused.
 	ec.Status.Phase = api.ClusterPhaseRunning
-	ec, err = r.etcdCRCli.EtcdV1beta2().EtcdClusters(er.Namespace).Create(ctx, ec, metav1.CreateOptions{})
+	ec, err = r.etcdCRCli.EtcdV1beta2().EtcdClusters(namespace).UpdateStatus(ctx, ec, metav1.UpdateOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to create restored EtcdCluster (%s/%s): %v", er.Namespace, clusterName, err)
+		return fmt.Errorf("failed to update status of restored EtcdCluster (%s/%s): %v", namespace, clusterName, err)
 	}
 
-	err = r.createSeedMember(ctx, ec, r.mySvcAddr, er.Namespace, clusterName, ec.AsOwner())
+	// Record the backup before the seed member is created, so that the backup server serves the backup that was verified.
+	err = r.setPhase(ctx, er, api.RestorePhaseFetching, fmt.Sprintf("Creating the seed member to fetch backup %s", er.Status.BackupPath))
+	if err != nil {
+		return err
+	}
+	err = r.createSeedMember(ctx, ec, r.mySvcAddr, er, ec.AsOwner())
 	if err != nil {
 		return fmt.Errorf("failed to create seed member for cluster (%s): %v", clusterName, err)
 	}
+	return nil
+}
 
-	// Retry updating the etcdcluster CR spec.paused=false. The etcd-operator will update the CR once so there needs to be a single retry in case of conflict
-	err = retryutil.Retry(2, 1, func() (bool, error) {
-		ec, err = r.etcdCRCli.EtcdV1beta2().EtcdClusters(er.Namespace).Get(ctx, clusterName, metav1.GetOptions{})
-		if err != nil {
-			return false, err
-		}
-		ec.Spec.Paused = false
-		_, err = r.etcdCRCli.EtcdV1beta2().EtcdClusters(er.Namespace).Update(ctx, ec, metav1.UpdateOptions{})
-		if err != nil {
-			if apierrors.IsConflict(err) {
-				return false, nil
-			}
-			return false, err
+// createRestoredCluster creates the restored EtcdCluster ec of er, paused and annotated with the UID of er.
+// It returns the restored EtcdCluster of er instead, if that exists already.
+func (r *Restore) createRestoredCluster(ctx context.Context, ec *api.EtcdCluster, er *api.EtcdRestore) (*api.EtcdCluster, error) {
+	ec.Annotations = maps.Clone(ec.Annotations)
+	if ec.Annotations == nil {
+		ec.Annotations = map[string]string{}
+	}
+	ec.Annotations[k8sutil.AnnotationRestoreUID] = string(er.UID)
+	ec.Spec.Paused = true
+	clusters := r.etcdCRCli.EtcdV1beta2().EtcdClusters(ec.Namespace)
+	created, err := clusters.Create(ctx, ec, metav1.CreateOptions{})
+	if k8sutil.IsKubernetesResourceAlreadyExistError(err) {
+		created, err = clusters.Get(ctx, ec.Name, metav1.GetOptions{})
+		if err == nil && !restoredBy(created, er) {
+			// E.g. the reference EtcdCluster is not deleted completely yet.
+			return nil, fmt.Errorf("restored EtcdCluster (%s/%s) already exists", ec.Namespace, ec.Name)
 		}
-		return true, nil
-	})
+	}
 	if err != nil {
-		return fmt.Errorf("failed to update etcdcluster CR to spec.paused=false: %v", err)
+		return nil, fmt.Errorf("failed to create restored EtcdCluster (%s/%s): %v", ec.Namespace, ec.Name, err)
 	}
-	return nil
+	return created, nil
+}
+
+// restoredBy tells whether ec is the restored EtcdCluster created by er, before the restore operator was stopped.
+func restoredBy(ec *api.EtcdCluster, er *api.EtcdRestore) bool {
+	return len(er.UID) != 0 && ec.Annotations[k8sutil.AnnotationRestoreUID] == string(er.UID)
+}
+
+// newTargetCluster returns a copy of the reference EtcdCluster ec, named name in namespace.
+// The copy is not owned by the owners of ec, and does not take over its backup policy,
+// so that it does not write backups next to the backups of ec.
+func newTargetCluster(ec *api.EtcdCluster, namespace, name string) *api.EtcdCluster {
+	target := &api.EtcdCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      ec.ObjectMeta.Labels,
+			Annotations: ec.ObjectMeta.Annotations,
+		},
+		Spec: *ec.Spec.DeepCopy(),
+	}
+	target.Spec.Backup = nil
+	target.Spec.UpgradePolicy = nil
+	return target
 }
 
-func (r *Restore) createSeedMember(ctx context.Context, ec *api.EtcdCluster, svcAddr, namespace string, clusterName string, owner metav1.OwnerReference) error {
+// createSeedMember creates the seed member of ec, which fetches the backup of er from the backup server at svcAddr
+// with a new restore token, or from the backup storage itself if er.Spec.Direct is set.
+func (r *Restore) createSeedMember(ctx context.Context, ec *api.EtcdCluster, svcAddr string, er *api.EtcdRestore, owner metav1.OwnerReference) error {
+	fetcher, err := r.newBackupFetcher(ctx, ec, svcAddr, er, owner)
+	if err != nil {
+		return err
+	}
 	m := &etcdutil.Member{
-		Name:         k8sutil.UniqueMemberName(clusterName),
-		Namespace:    namespace,
+		Name:         k8sutil.UniqueMemberName(ec.Name),
+		Namespace:    ec.Namespace,
 		SecurePeer:   ec.Spec.TLS.IsSecurePeer(),
 		SecureClient: ec.Spec.TLS.IsSecureClient(),
 	}
@@ -224,9 +355,8 @@ func (r *Restore) createSeedMember(ctx context.Context, ec *api.EtcdCluster, svc
 		m.ClusterDomain = ec.Spec.Pod.ClusterDomain
 	}
 	ms := etcdutil.NewMemberSet(m)
-	backupURL := backupapi.BackupURLForRestore("http", svcAddr, clusterName, namespace)
 	ec.SetDefaults()
-	pod, err := k8sutil.NewSeedMemberPod(ctx, r.kubecli, clusterName, namespace, ms, m, ec.Spec, owner, backupURL)
+	pod, err := k8sutil.NewSeedMemberPod(ctx, r.kubecli, ec.Name, ec.Namespace, ms, m, ec.Spec, owner, fetcher)
 	if err != nil {
 		return err
 	}
@@ -234,6 +364,54 @@ func (r *Restore) createSeedMember(ctx context.Context, ec *api.EtcdCluster, svc
 	return err
 }
 
+// newBackupFetcher returns the fetcher of the backup of er for the seed member of ec.
+func (r *Restore) newBackupFetcher(ctx context.Context, ec *api.EtcdCluster, svcAddr string, er *api.EtcdRestore, owner metav1.OwnerReference) (*k8sutil.BackupFetcher, error) {
+	if er.Spec.Direct {
+		// The seed member reads the backup that was verified, rather than selecting one itself.
+		st := er.Spec.BackupStorageType
+		return k8sutil.NewDirectBackupFetcher(r.seedImage, api.RestoreSpec{
+			BackupStorageType: st,
+			RestoreSource:     *er.Spec.RestoreSource.WithPath(st, er.Status.BackupPath),
+			Encryption:        er.Spec.Encryption,
+		})
+	}
+	if err := r.createRestoreToken(ctx, ec, owner); err != nil {
+		return nil, err
+	}
+	backupURL := backupapi.BackupURLForRestore("https", svcAddr, er.Name, er.Namespace)
+	return k8sutil.NewURLBackupFetcher(backupURL, ec.Name), nil
+}
+
+// createRestoreToken creates the secret with a new token the seed member of ec downloads its backup with,
+// and the CA of the backup server. It is deleted once the seed member is ready, see deleteRestoreToken.
+func (r *Restore) createRestoreToken(ctx context.Context, ec *api.EtcdCluster, owner metav1.OwnerReference) error {
+	_, caPEM, err := r.servingCert(ctx)
+	if err != nil {
+		return err
+	}
+	secret := k8sutil.NewRestoreTokenSecret(ec.Name, ec.Namespace, rand.Text(), caPEM, owner)
+	secrets := r.kubecli.CoreV1().Secrets(ec.Namespace)
+	_, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+	if k8sutil.IsKubernetesResourceAlreadyExistError(err) {
+		_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create restore token: %v", err)
+	}
+	return nil
+}
+
+// deleteRestoreToken deletes the restore token of the cluster restored by er, if it exists.
+// The seed member may download its backup again until it is ready, e.g. if its init containers are restarted.
+func (r *Restore) deleteRestoreToken(ctx context.Context, er *api.EtcdRestore) error {
+	namespace, clusterName := er.RestoredCluster()
+	err := r.kubecli.CoreV1().Secrets(namespace).Delete(ctx, k8sutil.RestoreTokenSecretName(clusterName), metav1.DeleteOptions{})
+	if err != nil && !k8sutil.IsKubernetesResourceNotFoundError(err) {
+		return fmt.Errorf("failed to delete restore token: %v", err)
+	}
+	return nil
+}
+
 func (r *Restore) deleteClusterResourcesCompletely(ctx context.Context, namespace string, clusterName string) error {
 	// Delete etcd pods
 	err := r.kubecli.CoreV1().Pods(namespace).Delete(ctx, clusterName, *metav1.NewDeleteOptions(0))
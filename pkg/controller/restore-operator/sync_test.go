@@ -0,0 +1,215 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/on2itsecurity/etcd-operator/pkg/generated/clientset/versioned/fake"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
+
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// newReferenceCluster returns a reference EtcdCluster with an owner, a backup policy and an upgrade policy.
+func newReferenceCluster() *api.EtcdCluster {
+	return &api.EtcdCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "example",
+			Namespace:       "default",
+			Labels:          map[string]string{"app": "etcd"},
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "v1", Kind: "ConfigMap", Name: "owner", UID: "owner-uid"}},
+		},
+		Spec: api.ClusterSpec{
+			Size: 1,
+			Backup: &api.ClusterBackupPolicy{
+				StorageType:  api.BackupStorageTypeS3,
+				BackupSource: api.BackupSource{S3: &api.S3BackupSource{Path: "bucket/example", AWSSecret: "aws"}},
+			},
+			UpgradePolicy: &api.UpgradePolicy{BackupBeforeUpgrade: true},
+		},
+	}
+}
+
+func TestNewTargetCluster(t *testing.T) {
+	ec := newReferenceCluster()
+	target := newTargetCluster(ec, "staging", "copy")
+
+	if target.Name != "copy" || target.Namespace != "staging" {
+		t.Errorf("target get=%s/%s, want=staging/copy", target.Namespace, target.Name)
+	}
+	if target.Labels["app"] != "etcd" {
+		t.Errorf("target labels get=%v, want the labels of the reference", target.Labels)
+	}
+	if len(target.OwnerReferences) != 0 || target.Spec.Backup != nil || target.Spec.UpgradePolicy != nil {
+		t.Errorf("target keeps the owners, backup or upgrade policy of the reference: %+v", target)
+	}
+	if target.Spec.Size != ec.Spec.Size {
+		t.Errorf("target size get=%d, want=%d", target.Spec.Size, ec.Spec.Size)
+	}
+	if ec.Spec.Backup == nil || ec.Spec.UpgradePolicy == nil {
+		t.Error("reference EtcdCluster is modified")
+	}
+}
+
+// newLocalBackupDir returns the directory of a Local backup storage with the backup etcd.backup.
+func newLocalBackupDir(t *testing.T) string {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "etcd.backup"), []byte("snapshot"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestPrepareSeedTarget(t *testing.T) {
+	ctx := context.Background()
+	dir := newLocalBackupDir(t)
+	er := &api.EtcdRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "verify", Namespace: "default"},
+		Spec: api.RestoreSpec{
+			BackupStorageType: api.BackupStorageTypeLocal,
+			RestoreSource:     api.RestoreSource{Local: &api.LocalRestoreSource{Path: "etcd.backup"}},
+			EtcdCluster:       api.EtcdClusterRef{Name: "example"},
+			Target:            &api.RestoreTarget{Name: "copy"},
+		},
+	}
+	etcdCRCli := fake.NewSimpleClientset(er, newReferenceCluster())
+	r := &Restore{
+		logger:            logrus.WithField("pkg", "test"),
+		operatorNamespace: "etcd",
+		mySvcAddr:         "etcd-restore-operator.etcd:19999",
+		backupDir:         dir,
+		kubecli:           kubefake.NewSimpleClientset(),
+		etcdCRCli:         etcdCRCli,
+	}
+
+	if err := r.prepareSeed(ctx, er); err != nil {
+		t.Fatal(err)
+	}
+	if er.Status.Phase != api.RestorePhaseFetching || er.Status.BackupPath != "etcd.backup" {
+		t.Errorf("restore status get=%+v, want fetching etcd.backup", er.Status)
+	}
+	clusters := etcdCRCli.EtcdV1beta2().EtcdClusters("default")
+	if _, err := clusters.Get(ctx, "example", metav1.GetOptions{}); err != nil {
+		t.Errorf("reference EtcdCluster is not left running: %v", err)
+	}
+	target, err := clusters.Get(ctx, "copy", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !target.Spec.Paused || len(target.OwnerReferences) != 0 || target.Spec.Backup != nil || target.Spec.UpgradePolicy != nil {
+		t.Errorf("target EtcdCluster get=%+v, want paused, without owners, backup and upgrade policy", target)
+	}
+	pods, err := r.kubecli.CoreV1().Pods("default").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pods.Items) != 1 || pods.Items[0].OwnerReferences[0].UID != target.UID {
+		t.Errorf("pods get=%d, want the seed member of the target", len(pods.Items))
+	}
+}
+
+func TestPrepareSeedResume(t *testing.T) {
+	ctx := context.Background()
+	dir := newLocalBackupDir(t)
+	restoredBy := func(ec *api.EtcdCluster, uid string) *api.EtcdCluster {
+		ec.Annotations = map[string]string{k8sutil.AnnotationRestoreUID: uid}
+		ec.Spec.Paused = true
+		return ec
+	}
+	newTarget := func() *api.EtcdCluster {
+		return newTargetCluster(newReferenceCluster(), "default", "copy")
+	}
+
+	tests := []struct {
+		target   bool
+		phase    api.RestorePhase
+		clusters []runtime.Object
+		wDelete  bool
+		wErr     bool
+	}{
+		// stopped before the reference EtcdCluster was deleted
+		{phase: api.RestorePhaseValidating, clusters: []runtime.Object{newReferenceCluster()}, wDelete: true},
+		{phase: api.RestorePhaseDeleting, clusters: []runtime.Object{newReferenceCluster()}, wDelete: true},
+		// stopped after the restored EtcdCluster was created
+		{phase: api.RestorePhaseDeleting, clusters: []runtime.Object{restoredBy(newReferenceCluster(), "restore-uid")}},
+		// stopped after the reference EtcdCluster was deleted, its spec is lost
+		{phase: api.RestorePhaseDeleting, wErr: true},
+		{target: true, phase: api.RestorePhaseValidating, clusters: []runtime.Object{newReferenceCluster()}},
+		{target: true, phase: api.RestorePhaseValidating, clusters: []runtime.Object{newReferenceCluster(), restoredBy(newTarget(), "restore-uid")}},
+		// the target is not created by the restore
+		{target: true, phase: api.RestorePhaseValidating, clusters: []runtime.Object{newReferenceCluster(), newTarget()}, wErr: true},
+		{target: true, phase: api.RestorePhaseValidating, clusters: []runtime.Object{newReferenceCluster(), restoredBy(newTarget(), "other-uid")}, wErr: true},
+	}
+	for i, tt := range tests {
+		er := &api.EtcdRestore{
+			ObjectMeta: metav1.ObjectMeta{Name: "example", Namespace: "default", UID: "restore-uid"},
+			Spec: api.RestoreSpec{
+				BackupStorageType: api.BackupStorageTypeLocal,
+				RestoreSource:     api.RestoreSource{Local: &api.LocalRestoreSource{Path: "etcd.backup"}},
+				EtcdCluster:       api.EtcdClusterRef{Name: "example"},
+			},
+		}
+		if tt.target {
+			er.Spec.Target = &api.RestoreTarget{Name: "copy"}
+		}
+		er.Status.SetPhase(tt.phase, "")
+		etcdCRCli := fake.NewSimpleClientset(append(tt.clusters, er)...)
+		r := &Restore{
+			logger:            logrus.WithField("pkg", "test"),
+			operatorNamespace: "etcd",
+			mySvcAddr:         "etcd-restore-operator.etcd:19999",
+			backupDir:         dir,
+			kubecli:           kubefake.NewSimpleClientset(),
+			etcdCRCli:         etcdCRCli,
+		}
+
+		err := r.prepareSeed(ctx, er)
+		if (err != nil) != tt.wErr {
+			t.Errorf("#%d: err get=%v, want err=%v", i, err, tt.wErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		deleted := false
+		for _, a := range etcdCRCli.Actions() {
+			deleted = deleted || a.Matches("delete", "etcdclusters")
+		}
+		if deleted != tt.wDelete {
+			t.Errorf("#%d: reference EtcdCluster deleted get=%v, want=%v", i, deleted, tt.wDelete)
+		}
+		namespace, clusterName := er.RestoredCluster()
+		ec, err := etcdCRCli.EtcdV1beta2().EtcdClusters(namespace).Get(ctx, clusterName, metav1.GetOptions{})
+		if err != nil {
+			t.Errorf("#%d: restored EtcdCluster: %v", i, err)
+			continue
+		}
+		if !ec.Spec.Paused || ec.Annotations[k8sutil.AnnotationRestoreUID] != "restore-uid" {
+			t.Errorf("#%d: restored EtcdCluster get=%+v, want paused and annotated with the restore", i, ec.ObjectMeta)
+		}
+		pods, err := r.kubecli.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil || len(pods.Items) != 1 || er.Status.Phase != api.RestorePhaseFetching {
+			t.Errorf("#%d: phase get=%s, want=%s with one seed member, %v", i, er.Status.Phase, api.RestorePhaseFetching, err)
+		}
+	}
+}
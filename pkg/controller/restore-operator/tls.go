@@ -0,0 +1,143 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/on2itsecurity/etcd-operator/pkg/util/k8sutil"
+	"github.com/on2itsecurity/etcd-operator/pkg/util/tlsutil"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// servingCertSecret is the secret in the namespace of the restore operator
+	// with the certificate of the backup server, and the CA it is signed by.
+	servingCertSecret      = "etcd-restore-operator-tls"
+	servingCertValidity    = 365 * 24 * time.Hour
+	servingCertRenewBefore = 30 * 24 * time.Hour
+)
+
+// servingCert returns the certificate of the backup server and the PEM encoded CA to verify it with.
+// It issues a new certificate and CA if the secret does not exist yet, or its certificate is about to expire.
+func (r *Restore) servingCert(ctx context.Context) (*tls.Certificate, []byte, error) {
+	r.certMu.Lock()
+	defer r.certMu.Unlock()
+	now := time.Now()
+	if r.cert != nil && now.Before(r.cert.Leaf.NotAfter.Add(-servingCertRenewBefore)) {
+		return r.cert, r.caPEM, nil
+	}
+
+	secrets := r.kubecli.CoreV1().Secrets(r.operatorNamespace)
+	secret, err := secrets.Get(ctx, servingCertSecret, metav1.GetOptions{})
+	if err != nil && !k8sutil.IsKubernetesResourceNotFoundError(err) {
+		return nil, nil, fmt.Errorf("failed to get TLS secret (%s): %v", servingCertSecret, err)
+	}
+	exists := err == nil
+	if exists {
+		cert, err := tls.X509KeyPair(secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey])
+		if err == nil && now.Before(cert.Leaf.NotAfter.Add(-servingCertRenewBefore)) {
+			r.cert, r.caPEM = &cert, secret.Data["ca.crt"]
+			return r.cert, r.caPEM, nil
+		}
+		if err != nil {
+			r.logger.Warningf("reissuing invalid certificate in secret (%s): %v", servingCertSecret, err)
+		} else {
+			r.logger.Infof("renewing certificate in secret (%s) expiring at %v", servingCertSecret, cert.Leaf.NotAfter)
+		}
+	}
+
+	data, err := newServingCertData(r.servingDNSNames())
+	if err != nil {
+		return nil, nil, err
+	}
+	if exists {
+		secret.Type = v1.SecretTypeTLS
+		secret.Data = data
+		_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	} else {
+		_, err = secrets.Create(ctx, &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: servingCertSecret, Namespace: r.operatorNamespace},
+			Type:       v1.SecretTypeTLS,
+			Data:       data,
+		}, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to save TLS secret (%s): %v", servingCertSecret, err)
+	}
+	cert, err := tls.X509KeyPair(data[v1.TLSCertKey], data[v1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, nil, err
+	}
+	r.cert, r.caPEM = &cert, data["ca.crt"]
+	return r.cert, r.caPEM, nil
+}
+
+// servingDNSNames returns the DNS names the backup server is reached at, through the service of the restore operator.
+func (r *Restore) servingDNSNames() []string {
+	host, _, err := net.SplitHostPort(r.mySvcAddr)
+	if err != nil {
+		host = r.mySvcAddr
+	}
+	svc, _, _ := strings.Cut(host, ".")
+	return []string{
+		svc,
+		fmt.Sprintf("%s.%s", svc, r.operatorNamespace),
+		fmt.Sprintf("%s.%s.svc", svc, r.operatorNamespace),
+	}
+}
+
+// newServingCertData returns the data of a kubernetes.io/tls secret with a certificate for dnsNames,
+// signed by a new CA. The key of the CA is not kept, a renewal issues a new CA.
+func newServingCertData(dnsNames []string) (map[string][]byte, error) {
+	caKey, err := tlsutil.NewPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	caCert, err := tlsutil.NewSelfSignedCACert(caKey, "etcd-restore-operator-ca", servingCertValidity)
+	if err != nil {
+		return nil, err
+	}
+	key, err := tlsutil.NewPrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	cert, err := tlsutil.NewSignedCert(tlsutil.CertConfig{
+		CommonName:  "etcd-restore-operator",
+		DNSNames:    dnsNames,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		Validity:    servingCertValidity,
+	}, key, caCert, caKey)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := tlsutil.EncodePrivateKeyPEM(key)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]byte{
+		v1.TLSCertKey:       tlsutil.EncodeCertPEM(cert),
+		v1.TLSPrivateKeyKey: keyPEM,
+		"ca.crt":            tlsutil.EncodeCertPEM(caCert),
+	}, nil
+}
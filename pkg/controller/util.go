@@ -1,47 +0,0 @@
-// Copyright 2017 The etcd-operator Authors
-//
-// Licensed under the Apache License, Version 2.0 (the "License");
-// you may not use this file except in compliance with the License.
-// You may obtain a copy of the License at
-//
-//     http://www.apache.org/licenses/LICENSE-2.0
-//
-// Unless required by applicable law or agreed to in writing, software
-// distributed under the License is distributed on an "AS IS" BASIS,
-// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
-// See the License for the specific language governing permissions and
-// limitations under the License.
-
-package controller
-
-import (
-	"time"
-)
-
-// panicTimer panics when it reaches the given duration.
-type panicTimer struct {
-	d   time.Duration
-	msg string
-	t   *time.Timer
-}
-
-func newPanicTimer(d time.Duration, msg string) *panicTimer {
-	return &panicTimer{
-		d:   d,
-		msg: msg,
-	}
-}
-
-func (pt *panicTimer) start() {
-	pt.t = time.AfterFunc(pt.d, func() {
-		panic(pt.msg)
-	})
-}
-
-// stop stops the timer and resets the elapsed duration.
-func (pt *panicTimer) stop() {
-	if pt.t != nil {
-		pt.t.Stop()
-		pt.t = nil
-	}
-}
@@ -25,6 +25,9 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+// DefaultEndpoint is the OSS service endpoint used if none is specified.
+const DefaultEndpoint = "http://oss-cn-hangzhou.aliyuncs.com"
+
 // OSSClient is a wrapper of OSS client that provides cleanup functionality.
 type OSSClient struct {
 	OSS *oss.Client
@@ -42,19 +45,29 @@ func NewClientFromSecret(ctx context.Context, kubecli kubernetes.Interface, name
 	if err != nil {
 		return nil, fmt.Errorf("failed to get k8s secret: %v", err)
 	}
+	w, err = NewClientFromSecretData(se.Data, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("%v, in secret \"%s\" in namespace \"%s\"", err, ossSecret, namespace)
+	}
+	return w, nil
+}
 
-	accessKeyID, ok := se.Data[api.AlibabaCloudSecretCredentialsAccessKeyID]
+// NewClientFromSecretData returns a OSS client based on the data of a k8s secret containing alibabacloud credentials.
+// The client uses DefaultEndpoint if endpoint is empty.
+func NewClientFromSecretData(data map[string][]byte, endpoint string) (*OSSClient, error) {
+	accessKeyID, ok := data[api.AlibabaCloudSecretCredentialsAccessKeyID]
 	if !ok {
-		return nil, fmt.Errorf("key \"%s\" not found in secret \"%s\" in namespace \"%s\"",
-			api.AlibabaCloudSecretCredentialsAccessKeyID, ossSecret, namespace)
+		return nil, fmt.Errorf("key \"%s\" not found", api.AlibabaCloudSecretCredentialsAccessKeyID)
 	}
 
-	accessKeySecret, ok := se.Data[api.AlibabaCloudSecretCredentialsAccessKeySecret]
+	accessKeySecret, ok := data[api.AlibabaCloudSecretCredentialsAccessKeySecret]
 	if !ok {
-		return nil, fmt.Errorf("key \"%s\" not found in secret \"%s\" in namespace \"%s\"",
-			api.AlibabaCloudSecretCredentialsAccessKeySecret, ossSecret, namespace)
+		return nil, fmt.Errorf("key \"%s\" not found", api.AlibabaCloudSecretCredentialsAccessKeySecret)
 	}
 
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
 	client, err := oss.New(endpoint, string(accessKeyID), string(accessKeySecret))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create OSS client: %v", err)
@@ -40,7 +40,16 @@ type S3Client struct {
 }
 
 // NewClientFromSecret returns a S3 client based on given k8s secret containing aws credentials.
-func NewClientFromSecret(ctx context.Context, kubecli kubernetes.Interface, namespace, endpoint, awsSecret string, forcePathStyle bool) (w *S3Client, err error) {
+func NewClientFromSecret(ctx context.Context, kubecli kubernetes.Interface, namespace, endpoint, awsSecret string, forcePathStyle bool) (*S3Client, error) {
+	se, err := kubecli.CoreV1().Secrets(namespace).Get(ctx, awsSecret, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("new S3 client failed: setup AWS config failed: get k8s secret failed: %v", err)
+	}
+	return NewClientFromSecretData(se.Data, endpoint, forcePathStyle)
+}
+
+// NewClientFromSecretData returns a S3 client based on the data of a k8s secret containing aws credentials.
+func NewClientFromSecretData(data map[string][]byte, endpoint string, forcePathStyle bool) (w *S3Client, err error) {
 	defer func() {
 		if err != nil {
 			err = fmt.Errorf("new S3 client failed: %v", err)
@@ -51,8 +60,9 @@ func NewClientFromSecret(ctx context.Context, kubecli kubernetes.Interface, name
 	if err != nil {
 		return nil, fmt.Errorf("failed to create aws config dir: (%v)", err)
 	}
-	so, err := setupAWSConfig(ctx, kubecli, namespace, awsSecret, endpoint, w.configDir, forcePathStyle)
+	so, err := setupAWSConfig(data, endpoint, w.configDir, forcePathStyle)
 	if err != nil {
+		w.Close()
 		return nil, fmt.Errorf("failed to setup aws config: (%v)", err)
 	}
 	sess, err := session.NewSessionWithOptions(*so)
@@ -68,8 +78,8 @@ func (w *S3Client) Close() {
 	os.RemoveAll(w.configDir)
 }
 
-// setupAWSConfig setup local AWS config/credential files from Kubernetes aws secret.
-func setupAWSConfig(ctx context.Context, kubecli kubernetes.Interface, ns, secret, endpoint, configDir string, forcePathStyle bool) (*session.Options, error) {
+// setupAWSConfig setup local AWS config/credential files from the data of Kubernetes aws secret.
+func setupAWSConfig(data map[string][]byte, endpoint, configDir string, forcePathStyle bool) (*session.Options, error) {
 	options := &session.Options{}
 	options.SharedConfigState = session.SharedConfigEnable
 
@@ -78,25 +88,20 @@ func setupAWSConfig(ctx context.Context, kubecli kubernetes.Interface, ns, secre
 
 	options.Config.S3ForcePathStyle = &forcePathStyle
 
-	se, err := kubecli.CoreV1().Secrets(ns).Get(ctx, secret, metav1.GetOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("setup AWS config failed: get k8s secret failed: %v", err)
-	}
-
-	creds := se.Data[api.AWSSecretCredentialsFileName]
+	creds := data[api.AWSSecretCredentialsFileName]
 	if len(creds) != 0 {
 		credsFile := path.Join(configDir, "credentials")
-		err = ioutil.WriteFile(credsFile, creds, 0600)
+		err := ioutil.WriteFile(credsFile, creds, 0600)
 		if err != nil {
 			return nil, fmt.Errorf("setup AWS config failed: write credentials file failed: %v", err)
 		}
 		options.SharedConfigFiles = append(options.SharedConfigFiles, credsFile)
 	}
 
-	config := se.Data[api.AWSSecretConfigFileName]
+	config := data[api.AWSSecretConfigFileName]
 	if len(config) != 0 {
 		configFile := path.Join(configDir, "config")
-		err = ioutil.WriteFile(configFile, config, 0600)
+		err := ioutil.WriteFile(configFile, config, 0600)
 		if err != nil {
 			return nil, fmt.Errorf("setup AWS config failed: write config file failed: %v", err)
 		}
@@ -15,11 +15,9 @@
 package s3factory
 
 import (
-	"context"
 	"testing"
 
 	"k8s.io/api/core/v1"
-	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestSetupAWSConfig(t *testing.T) {
@@ -27,10 +25,8 @@ func TestSetupAWSConfig(t *testing.T) {
 		Data: map[string][]byte{},
 	}
 
-	client := fake.NewSimpleClientset(sec)
-
 	e := "example.com"
-	opts, err := setupAWSConfig(context.Background(), client, "", "", e, "", false)
+	opts, err := setupAWSConfig(sec.Data, e, "", false)
 	if err != nil {
 		t.Error(err)
 	}
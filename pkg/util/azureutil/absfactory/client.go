@@ -36,9 +36,13 @@ func NewClientFromSecret(ctx context.Context, kubecli kubernetes.Interface, name
 	if err != nil {
 		return nil, fmt.Errorf("failed to get k8s secret: %v", err)
 	}
+	return NewClientFromSecretData(se.Data)
+}
 
-	accountName := string(se.Data[api.AzureSecretStorageAccount])
-	accountKey := string(se.Data[api.AzureSecretStorageKey])
+// NewClientFromSecretData returns an ABS client based on the data of a k8s secret containing azure credentials.
+func NewClientFromSecretData(data map[string][]byte) (*ABSClient, error) {
+	accountName := string(data[api.AzureSecretStorageAccount])
+	accountKey := string(data[api.AzureSecretStorageKey])
 	cred, err := azblob.NewSharedKeyCredential(accountName, accountKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create shared key credential: %v", err)
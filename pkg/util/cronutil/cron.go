@@ -0,0 +1,217 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cronutil parses cron schedules in the standard five field syntax,
+// as used by Kubernetes CronJobs, and computes their next run.
+package cronutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	// Time zones are loaded by name, also in images without a time zone database.
+	_ "time/tzdata"
+)
+
+// Schedule is a parsed cron schedule.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar tell whether the day of month and day of week are unrestricted.
+	// If both are restricted, a day matches if either matches.
+	domStar, dowStar bool
+
+	loc *time.Location
+}
+
+type bounds struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minutes = bounds{0, 59, nil}
+	hours   = bounds{0, 23, nil}
+	doms    = bounds{1, 31, nil}
+	months  = bounds{1, 12, map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// 7 is Sunday too.
+	dows = bounds{0, 7, map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses the cron schedule spec, evaluated in the time zone timeZone.
+// spec has the fields minute, hour, day of month, month and day of week, or is one of the descriptors
+// "@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight" and "@hourly".
+// timeZone is a name of the IANA time zone database, such as "Europe/Amsterdam", it defaults to UTC.
+func Parse(spec, timeZone string) (*Schedule, error) {
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone (%s): %v", timeZone, err)
+	}
+
+	if d, ok := descriptors[strings.TrimSpace(spec)]; ok {
+		spec = d
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule (%s): expected 5 fields, found %d", spec, len(fields))
+	}
+
+	s := &Schedule{loc: loc}
+	for i, f := range []struct {
+		bits *uint64
+		b    bounds
+	}{
+		{&s.minute, minutes},
+		{&s.hour, hours},
+		{&s.dom, doms},
+		{&s.month, months},
+		{&s.dow, dows},
+	} {
+		if *f.bits, err = parseField(fields[i], f.b); err != nil {
+			return nil, fmt.Errorf("invalid schedule (%s): %v", spec, err)
+		}
+	}
+	// Sunday is 0 for time.Weekday.
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domStar = strings.HasPrefix(fields[2], "*")
+	s.dowStar = strings.HasPrefix(fields[4], "*")
+	return s, nil
+}
+
+// parseField parses a comma separated list of "*", values and ranges, each with an optional step.
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, expr := range strings.Split(field, ",") {
+		rangeExpr, stepExpr, hasStep := strings.Cut(expr, "/")
+		var start, end int
+		switch {
+		case rangeExpr == "*":
+			start, end = b.min, b.max
+		default:
+			lo, hi, isRange := strings.Cut(rangeExpr, "-")
+			var err error
+			if start, err = parseValue(lo, b); err != nil {
+				return 0, err
+			}
+			end = start
+			if isRange {
+				if end, err = parseValue(hi, b); err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				end = b.max
+			}
+		}
+		if start > end {
+			return 0, fmt.Errorf("range (%s) starts after its end", rangeExpr)
+		}
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepExpr)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step (%s)", stepExpr)
+			}
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, b bounds) (int, error) {
+	if v, ok := b.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value (%s)", s)
+	}
+	if v < b.min || v > b.max {
+		return 0, fmt.Errorf("value (%d) out of range [%d, %d]", v, b.min, b.max)
+	}
+	return v, nil
+}
+
+// Location returns the time zone the schedule is evaluated in.
+func (s *Schedule) Location() *time.Location {
+	return s.loc
+}
+
+// Next returns the first time of the schedule after t, in the time zone of the schedule.
+// It returns the zero time if the schedule has no time within the next five years, e.g. "0 0 30 2 *".
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.In(s.loc)
+	// Start at the next whole minute.
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, s.loc).Add(time.Minute)
+	yearLimit := t.Year() + 5
+
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+	for s.month&(1<<uint(t.Month())) == 0 {
+		t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.loc)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+	for !s.dayMatches(t) {
+		t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.loc)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+	for s.hour&(1<<uint(t.Hour())) == 0 {
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, s.loc).Add(time.Hour)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+	for s.minute&(1<<uint(t.Minute())) == 0 {
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+	return t
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
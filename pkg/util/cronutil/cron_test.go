@@ -0,0 +1,86 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		spec     string
+		timeZone string
+		from     string
+		want     string
+	}{
+		{"* * * * *", "", "2026-03-04T05:06:07Z", "2026-03-04T05:07:00Z"},
+		{"*/15 * * * *", "", "2026-03-04T05:06:07Z", "2026-03-04T05:15:00Z"},
+		{"30 2 * * *", "", "2026-03-04T05:06:07Z", "2026-03-05T02:30:00Z"},
+		{"0 0 1 * *", "", "2026-12-15T00:00:00Z", "2027-01-01T00:00:00Z"},
+		{"0 9-17/4 * * mon-fri", "", "2026-03-06T18:00:00Z", "2026-03-09T09:00:00Z"},
+		{"0 0 * * 7", "", "2026-03-04T00:00:00Z", "2026-03-08T00:00:00Z"},
+		{"0 12 1,15 jan,jul *", "", "2026-01-15T12:00:00Z", "2026-07-01T12:00:00Z"},
+		// day of month or day of week, if both are restricted
+		{"0 0 13 * fri", "", "2026-03-01T00:00:00Z", "2026-03-06T00:00:00Z"},
+		{"0 0 29 2 *", "", "2026-03-01T00:00:00Z", "2028-02-29T00:00:00Z"},
+		{"@daily", "", "2026-03-04T05:06:07Z", "2026-03-05T00:00:00Z"},
+		{"@hourly", "", "2026-03-04T05:06:07Z", "2026-03-04T06:00:00Z"},
+		// wall-clock time in the time zone
+		{"0 1 * * *", "Europe/Amsterdam", "2026-03-04T05:06:07Z", "2026-03-05T00:00:00Z"},
+		{"0 1 * * *", "Europe/Amsterdam", "2026-07-04T05:06:07Z", "2026-07-04T23:00:00Z"},
+		// 02:30 does not exist on the day summer time starts
+		{"30 2 * * *", "Europe/Amsterdam", "2026-03-28T12:00:00Z", "2026-03-30T00:30:00Z"},
+		{"0 0 30 2 *", "", "2026-03-04T05:06:07Z", "0001-01-01T00:00:00Z"},
+	}
+	for i, tt := range tests {
+		s, err := Parse(tt.spec, tt.timeZone)
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+			continue
+		}
+		from, _ := time.Parse(time.RFC3339, tt.from)
+		want, _ := time.Parse(time.RFC3339, tt.want)
+		if got := s.Next(from); !got.Equal(want) {
+			t.Errorf("#%d: Next(%s) of %q get=%s, want=%s", i, tt.from, tt.spec, got.UTC().Format(time.RFC3339), tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		spec     string
+		timeZone string
+	}{
+		{"", ""},
+		{"* * * *", ""},
+		{"* * * * * *", ""},
+		{"60 * * * *", ""},
+		{"* 24 * * *", ""},
+		{"* * 0 * *", ""},
+		{"* * * 13 *", ""},
+		{"* * * * 8", ""},
+		{"5-1 * * * *", ""},
+		{"*/0 * * * *", ""},
+		{"a * * * *", ""},
+		{"@reboot", ""},
+		{"* * * * *", "Mars/Olympus_Mons"},
+	}
+	for i, tt := range tests {
+		if _, err := Parse(tt.spec, tt.timeZone); err == nil {
+			t.Errorf("#%d: expected an error for %q in %q", i, tt.spec, tt.timeZone)
+		}
+	}
+}
@@ -58,3 +58,52 @@ func RemoveMember(clientURLs []string, tc *tls.Config, id uint64) error {
 	cancel()
 	return err
 }
+
+// MemberRevision returns the kv store revision of the member serving clientURL.
+// It only talks to that member, so it also works when the cluster has lost quorum.
+func MemberRevision(clientURL string, tc *tls.Config) (int64, error) {
+	resp, err := MemberStatus(clientURL, tc)
+	if err != nil {
+		return 0, err
+	}
+	return resp.Header.Revision, nil
+}
+
+// MemberStatus returns the status of the member serving clientURL, e.g. its storage version and
+// whether a downgrade of the cluster is enabled.
+func MemberStatus(clientURL string, tc *tls.Config) (*clientv3.StatusResponse, error) {
+	cfg := clientv3.Config{
+		Endpoints:   []string{clientURL},
+		DialTimeout: constants.DefaultDialTimeout,
+		TLS:         tc,
+	}
+	etcdcli, err := clientv3.New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("get member status failed: creating etcd client failed: %v", err)
+	}
+	defer etcdcli.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultRequestTimeout)
+	resp, err := etcdcli.Status(ctx, clientURL)
+	cancel()
+	return resp, err
+}
+
+// Downgrade validates, enables or cancels a downgrade of the cluster to version, in the form "<major>.<minor>".
+func Downgrade(clientURLs []string, tc *tls.Config, action clientv3.DowngradeAction, version string) error {
+	cfg := clientv3.Config{
+		Endpoints:   clientURLs,
+		DialTimeout: constants.DefaultDialTimeout,
+		TLS:         tc,
+	}
+	etcdcli, err := clientv3.New(cfg)
+	if err != nil {
+		return err
+	}
+	defer etcdcli.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), constants.DefaultRequestTimeout)
+	_, err = etcdcli.Downgrade(ctx, action, version)
+	cancel()
+	return err
+}
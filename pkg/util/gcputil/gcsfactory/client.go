@@ -33,7 +33,17 @@ type GCSClient struct {
 }
 
 // NewClientFromSecret returns a GCS client based on given k8s secret containing azure credentials.
-func NewClientFromSecret(ctx context.Context, kubecli kubernetes.Interface, namespace, gcsSecret string) (w *GCSClient, err error) {
+func NewClientFromSecret(ctx context.Context, kubecli kubernetes.Interface, namespace, gcsSecret string) (*GCSClient, error) {
+	var data map[string][]byte
+	if se, err := kubecli.CoreV1().Secrets(namespace).Get(ctx, gcsSecret, metav1.GetOptions{}); err == nil {
+		data = se.Data
+	}
+	return NewClientFromSecretData(ctx, data)
+}
+
+// NewClientFromSecretData returns a GCS client based on the data of a k8s secret containing google credentials.
+// Without credentials in data, the client uses the default application credentials.
+func NewClientFromSecretData(ctx context.Context, data map[string][]byte) (w *GCSClient, err error) {
 	defer func() {
 		if err != nil {
 			err = fmt.Errorf("new GCS client failed: %v", err)
@@ -41,12 +51,10 @@ func NewClientFromSecret(ctx context.Context, kubecli kubernetes.Interface, name
 	}()
 
 	var authOptions []option.ClientOption
-	if se, err := kubecli.CoreV1().Secrets(namespace).Get(ctx, gcsSecret, metav1.GetOptions{}); err == nil {
-		if accessToken, ok := se.Data[api.GCPAccessToken]; ok {
-			authOptions = append(authOptions, option.WithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: string(accessToken)})))
-		} else if credentialsJson, ok := se.Data[api.GCPCredentialsJson]; ok {
-			authOptions = append(authOptions, option.WithCredentialsJSON(credentialsJson))
-		}
+	if accessToken, ok := data[api.GCPAccessToken]; ok {
+		authOptions = append(authOptions, option.WithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: string(accessToken)})))
+	} else if credentialsJson, ok := data[api.GCPCredentialsJson]; ok {
+		authOptions = append(authOptions, option.WithCredentialsJSON(credentialsJson))
 	}
 
 	gcs, err := storage.NewClient(ctx, authOptions...)
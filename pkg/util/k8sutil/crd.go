@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/crds"
 	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
 	"github.com/on2itsecurity/etcd-operator/pkg/util/retryutil"
 
@@ -52,43 +53,25 @@ func listClustersURI(ns string) string {
 	return fmt.Sprintf("/apis/%s/namespaces/%s/%s", api.SchemeGroupVersion.String(), ns, api.EtcdClusterResourcePlural)
 }
 
-func CreateCRD(ctx context.Context, clientset apiextensionsclient.Interface, crdName, rkind, rplural, shortName string) error {
-	crd := &apiextensionsv1.CustomResourceDefinition{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: crdName,
-		},
-		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
-			Group: api.SchemeGroupVersion.Group,
-			Scope: apiextensionsv1.NamespaceScoped,
-			Names: apiextensionsv1.CustomResourceDefinitionNames{
-				Plural: rplural,
-				Kind:   rkind,
-			},
-			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
-				{
-					Name:       api.SchemeGroupVersion.Version,
-					Served:     true,
-					Storage:    true,
-					Deprecated: false,
-					Schema: &apiextensionsv1.CustomResourceValidation{
-						OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
-							Type:                   "object",
-							XPreserveUnknownFields: truePointer(),
-						},
-					},
-				},
-			},
-		},
-	}
-	if len(shortName) != 0 {
-		crd.Spec.Names.ShortNames = []string{shortName}
+// CreateCRD creates the CRD with the given name from the manifests generated from the API types.
+// An existing CRD is updated to the schema of this version of the operator.
+func CreateCRD(ctx context.Context, clientset apiextensionsclient.Interface, crdName string) error {
+	crd, err := crds.Get(crdName)
+	if err != nil {
+		return err
 	}
-	_, err := clientset.ApiextensionsV1().CustomResourceDefinitions().Create(ctx, crd, metav1.CreateOptions{})
-	if err != nil && !IsKubernetesResourceAlreadyExistError(err) {
+	_, err = clientset.ApiextensionsV1().CustomResourceDefinitions().Create(ctx, crd, metav1.CreateOptions{})
+	if err == nil || !IsKubernetesResourceAlreadyExistError(err) {
 		return err
 	}
 
-	return nil
+	old, err := clientset.ApiextensionsV1().CustomResourceDefinitions().Get(ctx, crdName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	crd.ResourceVersion = old.ResourceVersion
+	_, err = clientset.ApiextensionsV1().CustomResourceDefinitions().Update(ctx, crd, metav1.UpdateOptions{})
+	return err
 }
 
 func WaitCRDReady(ctx context.Context, clientset apiextensionsclient.Interface, crdName string) error {
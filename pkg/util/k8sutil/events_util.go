@@ -17,6 +17,7 @@ package k8sutil
 import (
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
@@ -50,6 +51,38 @@ func ReplacingDeadMemberEvent(memberName string, cl *api.EtcdCluster) *v1.Event
 	return event
 }
 
+func RecreatingDeadMemberEvent(memberName string, cl *api.EtcdCluster) *v1.Event {
+	event := newClusterEvent(cl)
+	event.Type = v1.EventTypeNormal
+	event.Reason = "Recreating Dead Member"
+	event.Message = fmt.Sprintf("The dead member %s is being recreated on its persistent volume", memberName)
+	return event
+}
+
+func RecoveringClusterEvent(from string, cl *api.EtcdCluster) *v1.Event {
+	event := newClusterEvent(cl)
+	event.Type = v1.EventTypeWarning
+	event.Reason = "Recovering Cluster"
+	event.Message = fmt.Sprintf("The cluster lost quorum and is being recovered from %s", from)
+	return event
+}
+
+func ClusterRecoveredEvent(from string, cl *api.EtcdCluster) *v1.Event {
+	event := newClusterEvent(cl)
+	event.Type = v1.EventTypeNormal
+	event.Reason = "Cluster Recovered"
+	event.Message = fmt.Sprintf("The cluster has been recovered from %s", from)
+	return event
+}
+
+func ReplacingOutdatedMemberEvent(memberName string, cl *api.EtcdCluster) *v1.Event {
+	event := newClusterEvent(cl)
+	event.Type = v1.EventTypeNormal
+	event.Reason = "Replacing Outdated Member"
+	event.Message = fmt.Sprintf("The member %s is being replaced to match the pod spec and TLS certificates", memberName)
+	return event
+}
+
 func MemberUpgradedEvent(memberName, oldVersion, newVersion string, cl *api.EtcdCluster) *v1.Event {
 	event := newClusterEvent(cl)
 	event.Type = v1.EventTypeNormal
@@ -58,6 +91,29 @@ func MemberUpgradedEvent(memberName, oldVersion, newVersion string, cl *api.Etcd
 	return event
 }
 
+func BackupPrunedEvent(path string, eb *api.EtcdBackup) *v1.Event {
+	event := newBackupEvent(eb)
+	event.Type = v1.EventTypeNormal
+	event.Reason = "Backup Pruned"
+	event.Message = fmt.Sprintf("Backup %s pruned by the retention policy", path)
+	return event
+}
+
+// maxEventPaths is the number of backup paths an event lists at most, to keep its message short.
+const maxEventPaths = 10
+
+func BackupsWouldBePrunedEvent(paths []string, eb *api.EtcdBackup) *v1.Event {
+	event := newBackupEvent(eb)
+	event.Type = v1.EventTypeNormal
+	event.Reason = "Backups Would Be Pruned"
+	listed := strings.Join(paths[:min(len(paths), maxEventPaths)], ", ")
+	if len(paths) > maxEventPaths {
+		listed += fmt.Sprintf(" and %d more", len(paths)-maxEventPaths)
+	}
+	event.Message = fmt.Sprintf("%d backups would be pruned by the retention policy, but dry run is set: %s", len(paths), listed)
+	return event
+}
+
 func newClusterEvent(cl *api.EtcdCluster) *v1.Event {
 	t := time.Now()
 	return &v1.Event{
@@ -82,3 +138,27 @@ func newClusterEvent(cl *api.EtcdCluster) *v1.Event {
 		Count:          int32(1),
 	}
 }
+
+func newBackupEvent(eb *api.EtcdBackup) *v1.Event {
+	t := time.Now()
+	return &v1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: eb.Name + "-",
+			Namespace:    eb.Namespace,
+		},
+		InvolvedObject: v1.ObjectReference{
+			APIVersion:      api.SchemeGroupVersion.String(),
+			Kind:            api.EtcdBackupResourceKind,
+			Name:            eb.Name,
+			Namespace:       eb.Namespace,
+			UID:             eb.UID,
+			ResourceVersion: eb.ResourceVersion,
+		},
+		Source: v1.EventSource{
+			Component: os.Getenv(constants.EnvOperatorPodName),
+		},
+		FirstTimestamp: metav1.Time{Time: t},
+		LastTimestamp:  metav1.Time{Time: t},
+		Count:          int32(1),
+	}
+}
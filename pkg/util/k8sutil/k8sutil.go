@@ -16,6 +16,8 @@ package k8sutil
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -37,6 +39,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	utilrand "k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp" // for gcp auth
 	"k8s.io/client-go/rest"
@@ -49,9 +52,16 @@ const (
 
 	etcdVolumeMountDir       = "/var/etcd"
 	dataDir                  = etcdVolumeMountDir + "/data"
+	forceNewClusterFlag      = "--force-new-cluster"
 	backupFile               = "/var/etcd/latest.backup"
 	etcdVersionAnnotationKey = "etcd.version"
+	tlsCertsAnnotationKey    = "etcd.tls-certs"
+	podTemplateAnnotationKey = "etcd.pod-template"
 	peerTLSDir               = "/etc/etcdtls/member/peer-tls"
+	restoreTokenVolumeName   = "restore-token"
+	restoreTokenDir          = "/etc/etcd-restore"
+	restoreSourceDir         = "/etc/etcd-restore/source"
+	restoreEncryptionDir     = "/etc/etcd-restore/encryption"
 	peerTLSVolume            = "member-peer-tls"
 	serverTLSDir             = "/etc/etcdtls/member/server-tls"
 	serverTLSVolume          = "member-server-tls"
@@ -68,6 +78,8 @@ const (
 	AnnotationScope = "etcd.database.coreos.com/scope"
 	//AnnotationClusterWide annotation value for cluster wide clusters.
 	AnnotationClusterWide = "clusterwide"
+	// AnnotationRestoreUID annotation name for the UID of the EtcdRestore that created a restored cluster.
+	AnnotationRestoreUID = "etcd.database.coreos.com/restore-uid"
 
 	// defaultDNSTimeout is the default maximum allowed time for the init container of the etcd pod
 	// to reverse DNS lookup its IP. The default behavior is to wait forever and has a value of 0.
@@ -82,6 +94,38 @@ func SetEtcdVersion(pod *v1.Pod, version string) {
 	pod.Annotations[etcdVersionAnnotationKey] = version
 }
 
+// GetTLSCertsHash returns the hash of the peer and server certificates the pod was created with.
+func GetTLSCertsHash(pod *v1.Pod) string {
+	return pod.Annotations[tlsCertsAnnotationKey]
+}
+
+// GetPodTemplateHash returns the hash of the pod template the pod was created with.
+func GetPodTemplateHash(pod *v1.Pod) string {
+	return pod.Annotations[podTemplateAnnotationKey]
+}
+
+// SetPodTemplateHash records the hash of the pod template on the pod.
+func SetPodTemplateHash(pod *v1.Pod, hash string) {
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[podTemplateAnnotationKey] = hash
+}
+
+// PodTemplateHash returns the hash of the parts of the cluster spec that make up the pods of the members.
+// The version is not part of it, as members are upgraded in place.
+func PodTemplateHash(cs api.ClusterSpec) string {
+	b, err := json.Marshal(struct {
+		Repository string         `json:"repository,omitempty"`
+		Pod        *api.PodPolicy `json:"pod,omitempty"`
+	}{cs.Repository, cs.Pod})
+	if err != nil {
+		panic(err)
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])[:16]
+}
+
 func GetPodNames(pods []*v1.Pod) []string {
 	if len(pods) == 0 {
 		return nil
@@ -98,31 +142,94 @@ func PVCNameFromMember(memberName string) string {
 	return memberName
 }
 
-func makeRestoreInitContainers(backupURL *url.URL, token, repo, version string, m *etcdutil.Member) []v1.Container {
-	cmd := fmt.Sprintf("etcdctl snapshot restore %[1]s"+
-		" --name %[2]s"+
-		" --initial-cluster %[2]s=%[3]s"+
-		" --initial-cluster-token %[4]s"+
-		" --initial-advertise-peer-urls %[3]s"+
-		" --data-dir %[5]s 2>/dev/termination-log", backupFile, m.Name, m.PeerURL(), token, dataDir)
+// FetchBackupContainerName is the name of the init container of a seed member that fetches the backup.
+const FetchBackupContainerName = "fetch-backup"
 
-	return []v1.Container{
-		{
-			Name:  "fetch-backup",
+// BackupFetcher is the init container of a seed member that saves the backup to restore from,
+// and the volumes it mounts.
+type BackupFetcher struct {
+	Container v1.Container
+	Volumes   []v1.Volume
+}
+
+// NewURLBackupFetcher returns a fetcher that downloads the backup from the restore operator at backupURL,
+// with the restore token of the cluster, see NewRestoreTokenSecret.
+func NewURLBackupFetcher(backupURL *url.URL, clusterName string) *BackupFetcher {
+	return &BackupFetcher{
+		Container: v1.Container{
+			Name:  FetchBackupContainerName,
 			Image: "curlimages/curl",
 			Command: []string{
 				"/bin/ash", "-ec",
 				fmt.Sprintf(`
-httpcode=$(curl --write-out %%\{http_code\} --silent --output %[1]s %[2]s)
+httpcode=$(curl --write-out %%\{http_code\} --silent --cacert %[3]s/%[4]s --header "Authorization: Bearer $(cat %[3]s/%[5]s)" --output %[1]s %[2]s)
 if [[ "$httpcode" != "200" ]]; then
 	echo "http status code: ${httpcode}" >> /dev/termination-log
 	cat %[1]s >> /dev/termination-log
 	exit 1
 fi
-					`, backupFile, backupURL.String()),
+					`, backupFile, backupURL.String(), restoreTokenDir, RestoreCAKey, RestoreTokenKey),
+			},
+			VolumeMounts: append(etcdVolumeMounts(), v1.VolumeMount{Name: restoreTokenVolumeName, MountPath: restoreTokenDir, ReadOnly: true}),
+		},
+		// The restore token is deleted once the pod is ready, which must not keep the pod from starting again.
+		Volumes: []v1.Volume{{
+			Name: restoreTokenVolumeName,
+			VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{
+				SecretName: RestoreTokenSecretName(clusterName),
+				Optional:   truePointer(),
+			}},
+		}},
+	}
+}
+
+// NewDirectBackupFetcher returns a fetcher that reads the backup of spec from the backup storage itself,
+// with the etcd-restore-seed command of image. The RestoreSource of spec must hold the exact path of the backup.
+func NewDirectBackupFetcher(image string, spec api.RestoreSpec) (*BackupFetcher, error) {
+	b, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	f := &BackupFetcher{
+		Container: v1.Container{
+			Name:  FetchBackupContainerName,
+			Image: image,
+			Command: []string{
+				"etcd-restore-seed",
+				"--restore-spec=" + string(b),
+				"--source-secret-dir=" + restoreSourceDir,
+				"--encryption-secret-dir=" + restoreEncryptionDir,
+				"--output=" + backupFile,
 			},
 			VolumeMounts: etcdVolumeMounts(),
 		},
+	}
+	addSecret := func(volumeName, secret, dir string) {
+		f.Volumes = append(f.Volumes, v1.Volume{
+			Name:         volumeName,
+			VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: secret}},
+		})
+		f.Container.VolumeMounts = append(f.Container.VolumeMounts, v1.VolumeMount{Name: volumeName, MountPath: dir, ReadOnly: true})
+	}
+	if secret := spec.RestoreSource.Secret(spec.BackupStorageType); len(secret) != 0 {
+		addSecret("restore-source", secret, restoreSourceDir)
+	}
+	if spec.Encryption != nil {
+		addSecret("restore-encryption", spec.Encryption.Secret, restoreEncryptionDir)
+	}
+	return f, nil
+}
+
+func makeRestoreInitContainers(fetcher *BackupFetcher, token, repo, version string, m *etcdutil.Member) []v1.Container {
+	cmd := fmt.Sprintf("etcdctl snapshot restore %[1]s"+
+		" --name %[2]s"+
+		" --initial-cluster %[2]s=%[3]s"+
+		" --initial-cluster-token %[4]s"+
+		" --initial-advertise-peer-urls %[3]s"+
+		" --data-dir %[5]s 2>/dev/termination-log", backupFile, m.Name, m.PeerURL(), token, dataDir)
+
+	return []v1.Container{
+		fetcher.Container,
 		{
 			Name:         "restore-datadir",
 			Image:        ImageName(repo, version),
@@ -299,9 +406,26 @@ func AddEtcdVolumeToPod(pod *v1.Pod, pvc *v1.PersistentVolumeClaim, tmpfs bool)
 	pod.Spec.Volumes = append(pod.Spec.Volumes, vol)
 }
 
-func addRecoveryToPod(pod *v1.Pod, token string, m *etcdutil.Member, cs api.ClusterSpec, backupURL *url.URL) {
+// AddForceNewClusterFlag makes the etcd container of the pod start a new one member cluster
+// from its existing data dir. All other members are dropped from the membership.
+func AddForceNewClusterFlag(pod *v1.Pod) {
+	pod.Spec.Containers[0].Command = append(pod.Spec.Containers[0].Command, forceNewClusterFlag)
+}
+
+// IsForceNewClusterPod tells whether the etcd container of the pod runs with --force-new-cluster.
+func IsForceNewClusterPod(pod *v1.Pod) bool {
+	for _, arg := range pod.Spec.Containers[0].Command {
+		if arg == forceNewClusterFlag {
+			return true
+		}
+	}
+	return false
+}
+
+func addRecoveryToPod(pod *v1.Pod, token string, m *etcdutil.Member, cs api.ClusterSpec, fetcher *BackupFetcher) {
 	pod.Spec.InitContainers = append(pod.Spec.InitContainers,
-		makeRestoreInitContainers(backupURL, token, cs.Repository, cs.Version, m)...)
+		makeRestoreInitContainers(fetcher, token, cs.Repository, cs.Version, m)...)
+	pod.Spec.Volumes = append(pod.Spec.Volumes, fetcher.Volumes...)
 }
 
 func addOwnerRefToObject(o metav1.Object, r metav1.OwnerReference) {
@@ -310,7 +434,8 @@ func addOwnerRefToObject(o metav1.Object, r metav1.OwnerReference) {
 
 // NewSeedMemberPod returns a Pod manifest for a seed member.
 // It's special that it has new token, and might need recovery init containers
-func NewSeedMemberPod(ctx context.Context, kubecli kubernetes.Interface, clusterName, clusterNamespace string, ms etcdutil.MemberSet, m *etcdutil.Member, cs api.ClusterSpec, owner metav1.OwnerReference, backupURL *url.URL) (*v1.Pod, error) {
+// that restore the backup the fetcher saves.
+func NewSeedMemberPod(ctx context.Context, kubecli kubernetes.Interface, clusterName, clusterNamespace string, ms etcdutil.MemberSet, m *etcdutil.Member, cs api.ClusterSpec, owner metav1.OwnerReference, fetcher *BackupFetcher) (*v1.Pod, error) {
 	token := uuid.New()
 	pod, err := newEtcdPod(ctx, kubecli, m, ms.PeerURLPairs(), clusterName, clusterNamespace, "new", token, cs)
 	if err != nil {
@@ -330,8 +455,8 @@ func NewSeedMemberPod(ctx context.Context, kubecli kubernetes.Interface, cluster
 			AddEtcdVolumeToPod(pod, nil, false)
 		}
 	}
-	if backupURL != nil {
-		addRecoveryToPod(pod, token, m, cs, backupURL)
+	if fetcher != nil {
+		addRecoveryToPod(pod, token, m, cs, fetcher)
 	}
 	applyPodPolicy(clusterName, pod, cs.Pod)
 	addOwnerRefToObject(pod.GetObjectMeta(), owner)
@@ -357,11 +482,13 @@ func newEtcdPod(ctx context.Context, kubecli kubernetes.Interface, m *etcdutil.M
 		"--listen-peer-urls=%s --listen-client-urls=%s --advertise-client-urls=%s "+
 		"--initial-cluster=%s --initial-cluster-state=%s",
 		dataDir, m.Name, m.PeerURL(), m.ListenPeerURL(), m.ListenClientURL(), m.ClientURL(), strings.Join(initialCluster, ","), state)
+	var certSecrets []*v1.Secret
 	if m.SecurePeer {
-		secret, err := kubecli.CoreV1().Secrets(clusterNamespace).Get(ctx, cs.TLS.Static.Member.PeerSecret, metav1.GetOptions{})
+		secret, err := kubecli.CoreV1().Secrets(clusterNamespace).Get(ctx, cs.TLS.PeerSecret(clusterName), metav1.GetOptions{})
 		if err != nil {
 			return nil, err
 		}
+		certSecrets = append(certSecrets, secret)
 		if secret.Type == v1.SecretTypeTLS {
 			commands += fmt.Sprintf(" --peer-client-cert-auth=true --peer-trusted-ca-file=%[1]s/ca.crt --peer-cert-file=%[1]s/tls.crt --peer-key-file=%[1]s/tls.key", peerTLSDir)
 		} else {
@@ -369,10 +496,11 @@ func newEtcdPod(ctx context.Context, kubecli kubernetes.Interface, m *etcdutil.M
 		}
 	}
 	if m.SecureClient {
-		secret, err := kubecli.CoreV1().Secrets(clusterNamespace).Get(ctx, cs.TLS.Static.Member.ServerSecret, metav1.GetOptions{})
+		secret, err := kubecli.CoreV1().Secrets(clusterNamespace).Get(ctx, cs.TLS.ServerSecret(clusterName), metav1.GetOptions{})
 		if err != nil {
 			return nil, err
 		}
+		certSecrets = append(certSecrets, secret)
 		if secret.Type == v1.SecretTypeTLS {
 			commands += fmt.Sprintf(" --client-cert-auth=true --trusted-ca-file=%[1]s/ca.crt --cert-file=%[1]s/tls.crt --key-file=%[1]s/tls.key", serverTLSDir)
 		} else {
@@ -391,7 +519,7 @@ func newEtcdPod(ctx context.Context, kubecli kubernetes.Interface, m *etcdutil.M
 
 	isTLSSecret := false
 	if cs.TLS.IsSecureClient() {
-		secret, err := kubecli.CoreV1().Secrets(clusterNamespace).Get(ctx, cs.TLS.Static.OperatorSecret, metav1.GetOptions{})
+		secret, err := kubecli.CoreV1().Secrets(clusterNamespace).Get(ctx, cs.TLS.OperatorSecret(clusterName), metav1.GetOptions{})
 		if err != nil {
 			return nil, err
 		}
@@ -420,7 +548,7 @@ func newEtcdPod(ctx context.Context, kubecli kubernetes.Interface, m *etcdutil.M
 			Name:      peerTLSVolume,
 		})
 		volumes = append(volumes, v1.Volume{Name: peerTLSVolume, VolumeSource: v1.VolumeSource{
-			Secret: &v1.SecretVolumeSource{SecretName: cs.TLS.Static.Member.PeerSecret},
+			Secret: &v1.SecretVolumeSource{SecretName: cs.TLS.PeerSecret(clusterName)},
 		}})
 	}
 	if m.SecureClient {
@@ -432,9 +560,9 @@ func newEtcdPod(ctx context.Context, kubecli kubernetes.Interface, m *etcdutil.M
 			Name:      operatorEtcdTLSVolume,
 		})
 		volumes = append(volumes, v1.Volume{Name: serverTLSVolume, VolumeSource: v1.VolumeSource{
-			Secret: &v1.SecretVolumeSource{SecretName: cs.TLS.Static.Member.ServerSecret},
+			Secret: &v1.SecretVolumeSource{SecretName: cs.TLS.ServerSecret(clusterName)},
 		}}, v1.Volume{Name: operatorEtcdTLSVolume, VolumeSource: v1.VolumeSource{
-			Secret: &v1.SecretVolumeSource{SecretName: cs.TLS.Static.OperatorSecret},
+			Secret: &v1.SecretVolumeSource{SecretName: cs.TLS.OperatorSecret(clusterName)},
 		}})
 	}
 
@@ -496,6 +624,10 @@ func newEtcdPod(ctx context.Context, kubecli kubernetes.Interface, m *etcdutil.M
 		},
 	}
 	SetEtcdVersion(pod, cs.Version)
+	pod.Annotations[podTemplateAnnotationKey] = PodTemplateHash(cs)
+	if len(certSecrets) > 0 {
+		pod.Annotations[tlsCertsAnnotationKey] = certsHash(certSecrets...)
+	}
 	return pod, nil
 }
 
@@ -527,6 +659,14 @@ func MustNewKubeClient() kubernetes.Interface {
 	return kubernetes.NewForConfigOrDie(cfg)
 }
 
+func MustNewDynamicClient() dynamic.Interface {
+	cfg, err := InClusterConfig()
+	if err != nil {
+		panic(err)
+	}
+	return dynamic.NewForConfigOrDie(cfg)
+}
+
 func InClusterConfig() (*rest.Config, error) {
 	cfg, err := rest.InClusterConfig()
 	if err != nil {
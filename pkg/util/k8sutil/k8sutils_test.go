@@ -116,3 +116,25 @@ func TestGoGCEnvFromResourcesNoLimit(t *testing.T) {
 		t.Errorf("expect envVar=%v, got=%v", expected, envVar)
 	}
 }
+
+func TestNewDirectBackupFetcher(t *testing.T) {
+	spec := api.RestoreSpec{
+		BackupStorageType: api.BackupStorageTypeS3,
+		RestoreSource:     api.RestoreSource{S3: &api.S3RestoreSource{Path: "bucket/etcd.backup_v1", AWSSecret: "aws"}},
+		Encryption:        &api.BackupEncryption{Secret: "etcd-backup-key"},
+	}
+	f, err := NewDirectBackupFetcher("etcd-operator:latest", spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Container.Image != "etcd-operator:latest" {
+		t.Errorf("expect image=etcd-operator:latest, get=%s", f.Container.Image)
+	}
+	secrets := map[string]bool{}
+	for _, v := range f.Volumes {
+		secrets[v.Secret.SecretName] = true
+	}
+	if len(secrets) != 2 || !secrets["aws"] || !secrets["etcd-backup-key"] {
+		t.Errorf("expect volumes of secrets aws and etcd-backup-key, get=%v", secrets)
+	}
+}
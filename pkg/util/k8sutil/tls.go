@@ -16,10 +16,14 @@ package k8sutil
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
 
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
 	"k8s.io/client-go/kubernetes"
 	"github.com/on2itsecurity/etcd-operator/pkg/util/etcdutil"
 )
@@ -51,3 +55,82 @@ func GetTLSDataFromSecret(ctx context.Context, kubecli kubernetes.Interface, ns,
 		CAData:   secret.Data[etcdutil.CliCAFile],
 	}, nil
 }
+
+// NewTLSSecret returns a secret of type kubernetes.io/tls holding a certificate, its key and its CA.
+func NewTLSSecret(name, clusterName, namespace string, certPEM, keyPEM, caPEM []byte, owner metav1.OwnerReference) *v1.Secret {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    LabelsForCluster(clusterName),
+		},
+		Type: v1.SecretTypeTLS,
+		Data: map[string][]byte{
+			v1.TLSCertKey:       certPEM,
+			v1.TLSPrivateKeyKey: keyPEM,
+			"ca.crt":            caPEM,
+		},
+	}
+	addOwnerRefToObject(secret.GetObjectMeta(), owner)
+	return secret
+}
+
+const (
+	// RestoreTokenKey is the key of the token in the restore token secret.
+	RestoreTokenKey = "token"
+	// RestoreCAKey is the key of the CA of the backup server in the restore token secret.
+	RestoreCAKey = "ca.crt"
+)
+
+// RestoreTokenSecretName returns the name of the secret with the token the seed member of a restored cluster
+// downloads its backup with.
+func RestoreTokenSecretName(clusterName string) string {
+	return clusterName + "-restore-token"
+}
+
+// NewRestoreTokenSecret returns the secret with the token the seed member of the restored cluster downloads
+// its backup with, and the CA to verify the backup server with.
+func NewRestoreTokenSecret(clusterName, namespace, token string, caPEM []byte, owner metav1.OwnerReference) *v1.Secret {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      RestoreTokenSecretName(clusterName),
+			Namespace: namespace,
+			Labels:    LabelsForCluster(clusterName),
+		},
+		Data: map[string][]byte{
+			RestoreTokenKey: []byte(token),
+			RestoreCAKey:    caPEM,
+		},
+	}
+	addOwnerRefToObject(secret.GetObjectMeta(), owner)
+	return secret
+}
+
+// TLSCertsHash returns the hash of the peer and server certificates that new member pods of the cluster are created with.
+func TLSCertsHash(ctx context.Context, kubecli kubernetes.Interface, ns, clusterName string, tp *api.TLSPolicy) (string, error) {
+	var secrets []*v1.Secret
+	for _, name := range []string{tp.PeerSecret(clusterName), tp.ServerSecret(clusterName)} {
+		secret, err := kubecli.CoreV1().Secrets(ns).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return "", err
+		}
+		secrets = append(secrets, secret)
+	}
+	return certsHash(secrets...), nil
+}
+
+func certsHash(secrets ...*v1.Secret) string {
+	h := sha256.New()
+	for _, secret := range secrets {
+		keys := make([]string, 0, len(secret.Data))
+		for k := range secret.Data {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			h.Write([]byte(k))
+			h.Write(secret.Data[k])
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
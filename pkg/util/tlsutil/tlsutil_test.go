@@ -0,0 +1,74 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tlsutil
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func TestSignedCertVerifies(t *testing.T) {
+	caKey, err := NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := NewSelfSignedCACert(caKey, "etcd-ca", 24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := CertConfig{
+		CommonName:  "etcd-server",
+		DNSNames:    []string{"*.example.default.svc"},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		Validity:    48 * time.Hour,
+	}
+	cert, err := NewSignedCert(cfg, key, caCert, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.NotAfter.After(caCert.NotAfter) {
+		t.Errorf("certificate expires after its CA: %v > %v", cert.NotAfter, caCert.NotAfter)
+	}
+
+	// round trip through PEM
+	cert, err = ParseCertPEM(EncodeCertPEM(cert))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPEM, err := EncodePrivateKeyPEM(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParsePrivateKeyPEM(keyPEM); err != nil {
+		t.Fatal(err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	opts := x509.VerifyOptions{
+		DNSName:   "example-0000.example.default.svc",
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if _, err := cert.Verify(opts); err != nil {
+		t.Errorf("failed to verify certificate: %v", err)
+	}
+}
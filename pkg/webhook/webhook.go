@@ -0,0 +1,167 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhook implements the admission webhooks of the EtcdCluster, EtcdBackup and EtcdRestore resources.
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+
+	"github.com/sirupsen/logrus"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// MutatePath is the path of the mutating webhook, which sets the defaults of EtcdClusters.
+	MutatePath = "/mutate"
+	// ValidatePath is the path of the validating webhook.
+	ValidatePath = "/validate"
+)
+
+type admitFunc func(req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error)
+
+// NewHandler returns the handler serving the mutating and validating webhooks.
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(MutatePath, func(w http.ResponseWriter, r *http.Request) { serve(w, r, mutate) })
+	mux.HandleFunc(ValidatePath, func(w http.ResponseWriter, r *http.Request) { serve(w, r, validate) })
+	return mux
+}
+
+func serve(w http.ResponseWriter, r *http.Request, admit admitFunc) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var review admissionv1.AdmissionReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil || review.Request == nil {
+		http.Error(w, "invalid admission review", http.StatusBadRequest)
+		return
+	}
+
+	req := review.Request
+	resp, err := admit(req)
+	if err != nil {
+		logrus.Infof("denied %s of %s %s/%s: %v", req.Operation, req.Kind.Kind, req.Namespace, req.Name, err)
+		resp = &admissionv1.AdmissionResponse{
+			Result: &metav1.Status{
+				Status:  metav1.StatusFailure,
+				Code:    http.StatusUnprocessableEntity,
+				Reason:  metav1.StatusReasonInvalid,
+				Message: err.Error(),
+			},
+		}
+	}
+	resp.UID = req.UID
+	review.Request = nil
+	review.Response = resp
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(&review); err != nil {
+		logrus.Errorf("failed to write admission review: %v", err)
+	}
+}
+
+// mutate sets the defaults of EtcdClusters.
+func mutate(req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+	resp := &admissionv1.AdmissionResponse{Allowed: true}
+	if req.Kind.Kind != api.EtcdClusterResourceKind {
+		return resp, nil
+	}
+
+	cl := &api.EtcdCluster{}
+	if err := json.Unmarshal(req.Object.Raw, cl); err != nil {
+		return nil, fmt.Errorf("failed to decode EtcdCluster: %v", err)
+	}
+	cl.SetDefaults()
+
+	// "add" replaces the spec, and unlike "replace" it does not fail on EtcdClusters that are created without one.
+	patch, err := json.Marshal([]map[string]interface{}{{"op": "add", "path": "/spec", "value": cl.Spec}})
+	if err != nil {
+		return nil, err
+	}
+	pt := admissionv1.PatchTypeJSONPatch
+	resp.Patch = patch
+	resp.PatchType = &pt
+	return resp, nil
+}
+
+// validate runs the validation the operators do once a resource is stored,
+// and checks that EtcdCluster updates can be applied.
+func validate(req *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+	// The operators update the status of the resources with updates that leave the spec as it was.
+	if req.Operation == admissionv1.Delete || (req.Operation == admissionv1.Update && specUnchanged(req)) {
+		return &admissionv1.AdmissionResponse{Allowed: true}, nil
+	}
+
+	switch req.Kind.Kind {
+	case api.EtcdClusterResourceKind:
+		cl := &api.EtcdCluster{}
+		if err := json.Unmarshal(req.Object.Raw, cl); err != nil {
+			return nil, fmt.Errorf("failed to decode EtcdCluster: %v", err)
+		}
+		cl.SetDefaults()
+		if err := cl.Spec.Validate(); err != nil {
+			return nil, err
+		}
+		if req.Operation == admissionv1.Update {
+			old := &api.EtcdCluster{}
+			if err := json.Unmarshal(req.OldObject.Raw, old); err != nil {
+				return nil, fmt.Errorf("failed to decode EtcdCluster: %v", err)
+			}
+			old.SetDefaults()
+			if err := cl.Spec.ValidateUpdate(old.Spec); err != nil {
+				return nil, err
+			}
+		}
+	case api.EtcdBackupResourceKind:
+		eb := &api.EtcdBackup{}
+		if err := json.Unmarshal(req.Object.Raw, eb); err != nil {
+			return nil, fmt.Errorf("failed to decode EtcdBackup: %v", err)
+		}
+		if err := eb.Spec.Validate(); err != nil {
+			return nil, err
+		}
+	case api.EtcdRestoreResourceKind:
+		er := &api.EtcdRestore{}
+		if err := json.Unmarshal(req.Object.Raw, er); err != nil {
+			return nil, fmt.Errorf("failed to decode EtcdRestore: %v", err)
+		}
+		if err := er.Validate(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unexpected kind %s", req.Kind.Kind)
+	}
+	return &admissionv1.AdmissionResponse{Allowed: true}, nil
+}
+
+func specUnchanged(req *admissionv1.AdmissionRequest) bool {
+	var obj, old struct {
+		Spec interface{} `json:"spec"`
+	}
+	if err := json.Unmarshal(req.Object.Raw, &obj); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(req.OldObject.Raw, &old); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(obj.Spec, old.Spec)
+}
@@ -0,0 +1,287 @@
+// Copyright 2026 The etcd-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "github.com/on2itsecurity/etcd-operator/pkg/apis/etcd/v1beta2"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newCluster(size int, version string) *api.EtcdCluster {
+	return &api.EtcdCluster{
+		TypeMeta:   metav1.TypeMeta{Kind: api.EtcdClusterResourceKind, APIVersion: api.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: metav1.NamespaceDefault},
+		Spec:       api.ClusterSpec{Size: size, Version: version},
+	}
+}
+
+func review(t *testing.T, path string, op admissionv1.Operation, kind string, obj, old runtime.Object) *admissionv1.AdmissionResponse {
+	req := &admissionv1.AdmissionRequest{
+		UID:       types.UID("test-uid"),
+		Kind:      metav1.GroupVersionKind{Group: api.SchemeGroupVersion.Group, Version: api.SchemeGroupVersion.Version, Kind: kind},
+		Operation: op,
+		Object:    runtime.RawExtension{Object: obj},
+	}
+	if old != nil {
+		req.OldObject = runtime.RawExtension{Object: old}
+	}
+	body, err := json.Marshal(&admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{Kind: "AdmissionReview", APIVersion: admissionv1.SchemeGroupVersion.String()},
+		Request:  req,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	NewHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected status code %d: %s", rec.Code, rec.Body)
+	}
+	var out admissionv1.AdmissionReview
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Response.UID != req.UID {
+		t.Fatalf("response UID get=%s, want=%s", out.Response.UID, req.UID)
+	}
+	return out.Response
+}
+
+func TestValidateCluster(t *testing.T) {
+	withResources := newCluster(3, "v3.6.10")
+	withResources.Spec.Pod = &api.PodPolicy{Resources: v1.ResourceRequirements{
+		Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("1Gi")},
+	}}
+	withTLS := newCluster(3, "v3.6.10")
+	withTLS.Spec.TLS = &api.TLSPolicy{Operator: &api.OperatorTLS{}}
+	statusUpdate := newCluster(9, "v3.6.10")
+	statusUpdate.Status.Phase = api.ClusterPhaseRunning
+	withBackup := newCluster(3, "v3.6.10")
+	withBackup.Spec.Backup = &api.ClusterBackupPolicy{
+		StorageType:  api.BackupStorageTypeS3,
+		BackupPolicy: &api.BackupPolicy{Schedule: "@daily"},
+	}
+	withInvalidBackup := withBackup.DeepCopy()
+	withInvalidBackup.Spec.Backup.BackupPolicy.BackupIntervalInSecond = 60
+	withUpgradeBackup := withBackup.DeepCopy()
+	withUpgradeBackup.Spec.UpgradePolicy = &api.UpgradePolicy{BackupBeforeUpgrade: true}
+	withoutUpgradeBackupStorage := newCluster(3, "v3.6.10")
+	withoutUpgradeBackupStorage.Spec.UpgradePolicy = &api.UpgradePolicy{BackupBeforeUpgrade: true}
+
+	tests := []struct {
+		op       admissionv1.Operation
+		obj, old *api.EtcdCluster
+		wAllowed bool
+	}{{
+		op:       admissionv1.Create,
+		obj:      newCluster(3, ""),
+		wAllowed: true,
+	}, {
+		op:  admissionv1.Create,
+		obj: newCluster(0, "v3.6.10"),
+	}, {
+		op:  admissionv1.Create,
+		obj: newCluster(8, "v3.6.10"),
+	}, {
+		op:  admissionv1.Create,
+		obj: newCluster(3, "latest"),
+	}, {
+		op:       admissionv1.Update,
+		obj:      newCluster(5, "v3.6.10"),
+		old:      newCluster(3, "v3.5.21"),
+		wAllowed: true,
+	}, { // downgrade to the previous minor version
+		op:       admissionv1.Update,
+		obj:      newCluster(3, "v3.5.21"),
+		old:      newCluster(3, "v3.6.10"),
+		wAllowed: true,
+	}, { // downgrade to an earlier patch
+		op:       admissionv1.Update,
+		obj:      newCluster(3, "v3.6.1"),
+		old:      newCluster(3, "v3.6.10"),
+		wAllowed: true,
+	}, { // downgrade by more than one minor version
+		op:  admissionv1.Update,
+		obj: newCluster(3, "v3.4.37"),
+		old: newCluster(3, "v3.6.10"),
+	}, { // downgrade from a version without the downgrade API
+		op:  admissionv1.Update,
+		obj: newCluster(3, "v3.4.37"),
+		old: newCluster(3, "v3.5.21"),
+	}, { // resources are rolled out to the members
+		op:       admissionv1.Update,
+		obj:      withResources,
+		old:      newCluster(3, "v3.6.10"),
+		wAllowed: true,
+	}, { // TLS cannot be enabled
+		op:  admissionv1.Update,
+		obj: withTLS,
+		old: newCluster(3, "v3.6.10"),
+	}, { // status updates leave the spec as it was
+		op:       admissionv1.Update,
+		obj:      statusUpdate,
+		old:      newCluster(9, "v3.6.10"),
+		wAllowed: true,
+	}, {
+		op:       admissionv1.Create,
+		obj:      withBackup,
+		wAllowed: true,
+	}, { // both a schedule and an interval
+		op:  admissionv1.Create,
+		obj: withInvalidBackup,
+	}, {
+		op:       admissionv1.Create,
+		obj:      withUpgradeBackup,
+		wAllowed: true,
+	}, { // a backup before upgrade needs backup storage
+		op:  admissionv1.Create,
+		obj: withoutUpgradeBackupStorage,
+	}}
+
+	for i, tt := range tests {
+		var old runtime.Object
+		if tt.old != nil {
+			old = tt.old
+		}
+		resp := review(t, ValidatePath, tt.op, api.EtcdClusterResourceKind, tt.obj, old)
+		if resp.Allowed != tt.wAllowed {
+			t.Errorf("#%d: allowed get=%v, want=%v (%v)", i, resp.Allowed, tt.wAllowed, resp.Result)
+		}
+	}
+}
+
+func TestValidateBackupAndRestore(t *testing.T) {
+	backup := &api.EtcdBackup{Spec: api.BackupSpec{EtcdEndpoints: []string{"http://localhost:2379"}}}
+	restore := &api.EtcdRestore{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec:       api.RestoreSpec{EtcdCluster: api.EtcdClusterRef{Name: "test"}},
+	}
+	misnamedRestore := restore.DeepCopy()
+	misnamedRestore.Name = "other"
+	targetRestore := restore.DeepCopy()
+	targetRestore.Name = "copy"
+	targetRestore.Spec.Target = &api.RestoreTarget{Name: "copy", Namespace: "staging"}
+	namedTargetRestore := targetRestore.DeepCopy()
+	namedTargetRestore.Name = "verify-backup"
+	referenceTargetRestore := restore.DeepCopy()
+	referenceTargetRestore.Spec.Target = &api.RestoreTarget{Name: "test"}
+	selectorRestore := restore.DeepCopy()
+	selectorRestore.Spec.Selector = &api.RestoreSelector{Latest: true}
+	invalidSelectorRestore := restore.DeepCopy()
+	invalidSelectorRestore.Spec.Selector = &api.RestoreSelector{Latest: true, EtcdRevision: 42}
+	directRestore := restore.DeepCopy()
+	directRestore.Spec.Direct = true
+	directRestore.Spec.BackupStorageType = api.BackupStorageTypeS3
+	localDirectRestore := restore.DeepCopy()
+	localDirectRestore.Spec.Direct = true
+	localDirectRestore.Spec.BackupStorageType = api.BackupStorageTypeLocal
+	directTargetRestore := directRestore.DeepCopy()
+	directTargetRestore.Spec.Target = &api.RestoreTarget{Name: "copy"}
+	otherNamespaceDirectRestore := directRestore.DeepCopy()
+	otherNamespaceDirectRestore.Spec.Target = &api.RestoreTarget{Name: "copy", Namespace: "staging"}
+
+	tests := []struct {
+		kind     string
+		obj      runtime.Object
+		wAllowed bool
+	}{{
+		kind:     api.EtcdBackupResourceKind,
+		obj:      backup,
+		wAllowed: true,
+	}, {
+		kind: api.EtcdBackupResourceKind,
+		obj:  &api.EtcdBackup{},
+	}, {
+		kind:     api.EtcdRestoreResourceKind,
+		obj:      restore,
+		wAllowed: true,
+	}, {
+		kind: api.EtcdRestoreResourceKind,
+		obj:  misnamedRestore,
+	}, {
+		kind:     api.EtcdRestoreResourceKind,
+		obj:      targetRestore,
+		wAllowed: true,
+	}, { // the name need not be the target name
+		kind:     api.EtcdRestoreResourceKind,
+		obj:      namedTargetRestore,
+		wAllowed: true,
+	}, { // the target must not be the reference cluster
+		kind: api.EtcdRestoreResourceKind,
+		obj:  referenceTargetRestore,
+	}, {
+		kind:     api.EtcdRestoreResourceKind,
+		obj:      selectorRestore,
+		wAllowed: true,
+	}, { // more than one selector
+		kind: api.EtcdRestoreResourceKind,
+		obj:  invalidSelectorRestore,
+	}, {
+		kind:     api.EtcdRestoreResourceKind,
+		obj:      directRestore,
+		wAllowed: true,
+	}, { // the seed member can not read the volume of the restore operator
+		kind: api.EtcdRestoreResourceKind,
+		obj:  localDirectRestore,
+	}, {
+		kind:     api.EtcdRestoreResourceKind,
+		obj:      directTargetRestore,
+		wAllowed: true,
+	}, { // the seed member can not mount the secrets of another namespace
+		kind: api.EtcdRestoreResourceKind,
+		obj:  otherNamespaceDirectRestore,
+	}}
+
+	for i, tt := range tests {
+		resp := review(t, ValidatePath, admissionv1.Create, tt.kind, tt.obj, nil)
+		if resp.Allowed != tt.wAllowed {
+			t.Errorf("#%d: allowed get=%v, want=%v (%v)", i, resp.Allowed, tt.wAllowed, resp.Result)
+		}
+	}
+}
+
+func TestMutateCluster(t *testing.T) {
+	resp := review(t, MutatePath, admissionv1.Create, api.EtcdClusterResourceKind, newCluster(3, ""), nil)
+	if !resp.Allowed {
+		t.Fatalf("mutation denied: %v", resp.Result)
+	}
+	var patch []struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value api.ClusterSpec `json:"value"`
+	}
+	if err := json.Unmarshal(resp.Patch, &patch); err != nil {
+		t.Fatal(err)
+	}
+	if len(patch) != 1 || patch[0].Op != "add" || patch[0].Path != "/spec" {
+		t.Fatalf("unexpected patch: %s", resp.Patch)
+	}
+	if patch[0].Value.Version != api.DefaultEtcdVersion {
+		t.Errorf("version get=%s, want=%s", patch[0].Value.Version, api.DefaultEtcdVersion)
+	}
+}